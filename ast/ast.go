@@ -67,6 +67,20 @@ func (letStatement *LetStatement) String() string {
 	return out.String()
 }
 
+// ImportStatement binds the module of a file to a name, as in
+// import "lib/util.mk" as util.
+type ImportStatement struct {
+	Token token.Token // The 'import' token
+	Path  *StringLiteral
+	Name  *Identifier
+}
+
+func (importStatement *ImportStatement) statementNode()       {}
+func (importStatement *ImportStatement) TokenLiteral() string { return importStatement.Token.Literal }
+func (importStatement *ImportStatement) String() string {
+	return "import " + importStatement.Path.String() + " as " + importStatement.Name.String() + ";"
+}
+
 type Identifier struct {
 	Token token.Token
 	Value string
@@ -122,6 +136,15 @@ func (integerLiteral *IntegerLiteral) expressionNode()      {}
 func (integerLiteral *IntegerLiteral) TokenLiteral() string { return integerLiteral.Token.Literal }
 func (integerLiteral *IntegerLiteral) String() string       { return integerLiteral.Token.Literal }
 
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (floatLiteral *FloatLiteral) expressionNode()      {}
+func (floatLiteral *FloatLiteral) TokenLiteral() string { return floatLiteral.Token.Literal }
+func (floatLiteral *FloatLiteral) String() string       { return floatLiteral.Token.Literal }
+
 type PrefixExpression struct {
 	Token    token.Token
 	Operator string
@@ -315,6 +338,36 @@ func (indexExpression *IndexExpression) String() string {
 	return out.String()
 }
 
+// MemberExpression looks up a name in a module or hash, as in math.pi.
+type MemberExpression struct {
+	Token  token.Token // The '.' token
+	Left   Expression
+	Member *Identifier
+}
+
+func (memberExpression *MemberExpression) expressionNode() {}
+func (memberExpression *MemberExpression) TokenLiteral() string {
+	return memberExpression.Token.Literal
+}
+func (memberExpression *MemberExpression) String() string {
+	return "(" + memberExpression.Left.String() + "." + memberExpression.Member.String() + ")"
+}
+
+// ImportExpression evaluates to the module of a file, as in
+// import("lib/util.mk").
+type ImportExpression struct {
+	Token token.Token // The 'import' token
+	Path  Expression
+}
+
+func (importExpression *ImportExpression) expressionNode() {}
+func (importExpression *ImportExpression) TokenLiteral() string {
+	return importExpression.Token.Literal
+}
+func (importExpression *ImportExpression) String() string {
+	return "import(" + importExpression.Path.String() + ")"
+}
+
 type HashLiteral struct {
 	Token token.Token
 	Pairs map[Expression]Expression
@@ -326,8 +379,8 @@ func (hashLiteral *HashLiteral) String() string {
 	var out bytes.Buffer
 
 	pairs := []string{}
-	for key, value := range hashLiteral.Pairs {
-		pairs = append(pairs, key.String()+": "+value.String())
+	for _, key := range SortedKeys(hashLiteral) {
+		pairs = append(pairs, key.String()+": "+hashLiteral.Pairs[key].String())
 	}
 
 	out.WriteString("{")
@@ -355,6 +408,10 @@ func Modify(node Node, modifier ModifierFunc) Node {
 	case *IndexExpression:
 		node.Left, _ = Modify(node.Left, modifier).(Expression)
 		node.Index, _ = Modify(node.Index, modifier).(Expression)
+	case *MemberExpression:
+		node.Left, _ = Modify(node.Left, modifier).(Expression)
+	case *ImportExpression:
+		node.Path, _ = Modify(node.Path, modifier).(Expression)
 	case *IfExpression:
 		node.Condition, _ = Modify(node.Condition, modifier).(Expression)
 		node.Consequence, _ = Modify(node.Consequence, modifier).(*BlockStatement)
@@ -0,0 +1,586 @@
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+	"monkey/token"
+)
+
+// jsonNode is the wire format shared by every node kind. Each kind only
+// fills in the fields it needs; the others are omitted from the output.
+// "value" always holds a child node, the expression of a statement or the
+// path of an import. The values of literals have a field of their own per
+// type: "string" for identifiers and strings, "integer", "float" and
+// "boolean".
+type jsonNode struct {
+	Kind        string       `json:"kind"`
+	Token       *token.Token `json:"token,omitempty"`
+	Operator    string       `json:"operator,omitempty"`
+	Value       *jsonNode    `json:"value,omitempty"`
+	String      *string      `json:"string,omitempty"`
+	Integer     *int64       `json:"integer,omitempty"`
+	Float       *float64     `json:"float,omitempty"`
+	Boolean     *bool        `json:"boolean,omitempty"`
+	Name        *jsonNode    `json:"name,omitempty"`
+	Left        *jsonNode    `json:"left,omitempty"`
+	Right       *jsonNode    `json:"right,omitempty"`
+	Index       *jsonNode    `json:"index,omitempty"`
+	Condition   *jsonNode    `json:"condition,omitempty"`
+	Consequence *jsonNode    `json:"consequence,omitempty"`
+	Alternative *jsonNode    `json:"alternative,omitempty"`
+	Function    *jsonNode    `json:"function,omitempty"`
+	Body        *jsonNode    `json:"body,omitempty"`
+	Parameters  []*jsonNode  `json:"parameters,omitempty"`
+	Arguments   []*jsonNode  `json:"arguments,omitempty"`
+	Elements    []*jsonNode  `json:"elements,omitempty"`
+	Statements  []*jsonNode  `json:"statements,omitempty"`
+	Pairs       []jsonPair   `json:"pairs,omitempty"`
+}
+
+type jsonPair struct {
+	Key   *jsonNode `json:"key"`
+	Value *jsonNode `json:"value"`
+}
+
+// EncodeJSON encodes node and all of its children as JSON. Every node is
+// an object with a "kind" field naming its Go type, its token including
+// the source position, one field per child, and for literals a field
+// named after the type of their value.
+func EncodeJSON(node Node) ([]byte, error) {
+	encoded, err := encodeNode(node)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encoded)
+}
+
+// DecodeJSON rebuilds the tree encoded by EncodeJSON.
+func DecodeJSON(data []byte) (Node, error) {
+	var decoded jsonNode
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return decodeNode(&decoded)
+}
+
+// DecodeProgramJSON is like DecodeJSON but requires the root to be a Program.
+func DecodeProgramJSON(data []byte) (*Program, error) {
+	node, err := DecodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	program, ok := node.(*Program)
+	if !ok {
+		return nil, fmt.Errorf("expected Program, got %T", node)
+	}
+
+	return program, nil
+}
+
+func encodeNode(node Node) (*jsonNode, error) {
+	if node == nil {
+		return nil, nil
+	}
+
+	switch node := node.(type) {
+	case *Program:
+		statements, err := encodeStatements(node.Statements)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{Kind: "Program", Statements: statements}, nil
+	case *LetStatement:
+		name, err := encodeNode(identifierNode(node.Name))
+		if err != nil {
+			return nil, err
+		}
+		value, err := encodeNode(node.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{Kind: "LetStatement", Token: tokenOf(node.Token), Name: name, Value: value}, nil
+	case *ReturnStatement:
+		value, err := encodeNode(node.ReturnValue)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{Kind: "ReturnStatement", Token: tokenOf(node.Token), Value: value}, nil
+	case *ExpressionStatement:
+		value, err := encodeNode(node.Expression)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{Kind: "ExpressionStatement", Token: tokenOf(node.Token), Value: value}, nil
+	case *BlockStatement:
+		statements, err := encodeStatements(node.Statements)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{Kind: "BlockStatement", Token: tokenOf(node.Token), Statements: statements}, nil
+	case *Identifier:
+		return &jsonNode{Kind: "Identifier", Token: tokenOf(node.Token), String: &node.Value}, nil
+	case *IntegerLiteral:
+		return &jsonNode{Kind: "IntegerLiteral", Token: tokenOf(node.Token), Integer: &node.Value}, nil
+	case *FloatLiteral:
+		return &jsonNode{Kind: "FloatLiteral", Token: tokenOf(node.Token), Float: &node.Value}, nil
+	case *Boolean:
+		return &jsonNode{Kind: "Boolean", Token: tokenOf(node.Token), Boolean: &node.Value}, nil
+	case *StringLiteral:
+		return &jsonNode{Kind: "StringLiteral", Token: tokenOf(node.Token), String: &node.Value}, nil
+	case *PrefixExpression:
+		right, err := encodeNode(node.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{Kind: "PrefixExpression", Token: tokenOf(node.Token), Operator: node.Operator, Right: right}, nil
+	case *InfixExpression:
+		left, err := encodeNode(node.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := encodeNode(node.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{Kind: "InfixExpression", Token: tokenOf(node.Token), Operator: node.Operator, Left: left, Right: right}, nil
+	case *IfExpression:
+		condition, err := encodeNode(node.Condition)
+		if err != nil {
+			return nil, err
+		}
+		consequence, err := encodeNode(blockNode(node.Consequence))
+		if err != nil {
+			return nil, err
+		}
+		alternative, err := encodeNode(blockNode(node.Alternative))
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{
+			Kind:        "IfExpression",
+			Token:       tokenOf(node.Token),
+			Condition:   condition,
+			Consequence: consequence,
+			Alternative: alternative,
+		}, nil
+	case *FunctionLiteral:
+		return encodeFunction("FunctionLiteral", node.Token, node.Parameters, node.Body)
+	case *MacroLiteral:
+		return encodeFunction("MacroLiteral", node.Token, node.Parameters, node.Body)
+	case *CallExpression:
+		function, err := encodeNode(node.Function)
+		if err != nil {
+			return nil, err
+		}
+		arguments, err := encodeExpressions(node.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{Kind: "CallExpression", Token: tokenOf(node.Token), Function: function, Arguments: arguments}, nil
+	case *ArrayLiteral:
+		elements, err := encodeExpressions(node.Elements)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{Kind: "ArrayLiteral", Token: tokenOf(node.Token), Elements: elements}, nil
+	case *IndexExpression:
+		left, err := encodeNode(node.Left)
+		if err != nil {
+			return nil, err
+		}
+		index, err := encodeNode(node.Index)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{Kind: "IndexExpression", Token: tokenOf(node.Token), Left: left, Index: index}, nil
+	case *ImportStatement:
+		var path Node
+		if node.Path != nil {
+			path = node.Path
+		}
+		value, err := encodeNode(path)
+		if err != nil {
+			return nil, err
+		}
+		name, err := encodeNode(identifierNode(node.Name))
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{Kind: "ImportStatement", Token: tokenOf(node.Token), Name: name, Value: value}, nil
+	case *ImportExpression:
+		value, err := encodeNode(node.Path)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{Kind: "ImportExpression", Token: tokenOf(node.Token), Value: value}, nil
+	case *MemberExpression:
+		left, err := encodeNode(node.Left)
+		if err != nil {
+			return nil, err
+		}
+		member, err := encodeNode(identifierNode(node.Member))
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{Kind: "MemberExpression", Token: tokenOf(node.Token), Left: left, Name: member}, nil
+	case *HashLiteral:
+		pairs := []jsonPair{}
+		for _, key := range SortedKeys(node) {
+			encodedKey, err := encodeNode(key)
+			if err != nil {
+				return nil, err
+			}
+			encodedValue, err := encodeNode(node.Pairs[key])
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, jsonPair{Key: encodedKey, Value: encodedValue})
+		}
+		return &jsonNode{Kind: "HashLiteral", Token: tokenOf(node.Token), Pairs: pairs}, nil
+	default:
+		return nil, fmt.Errorf("cannot encode node of type %T", node)
+	}
+}
+
+func encodeFunction(kind string, tok token.Token, parameters []*Identifier, body *BlockStatement) (*jsonNode, error) {
+	encodedParameters := []*jsonNode{}
+	for _, parameter := range parameters {
+		encoded, err := encodeNode(identifierNode(parameter))
+		if err != nil {
+			return nil, err
+		}
+		encodedParameters = append(encodedParameters, encoded)
+	}
+
+	encodedBody, err := encodeNode(blockNode(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return &jsonNode{Kind: kind, Token: tokenOf(tok), Parameters: encodedParameters, Body: encodedBody}, nil
+}
+
+func encodeStatements(statements []Statement) ([]*jsonNode, error) {
+	encoded := []*jsonNode{}
+	for _, statement := range statements {
+		node, err := encodeNode(statement)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, node)
+	}
+	return encoded, nil
+}
+
+func encodeExpressions(expressions []Expression) ([]*jsonNode, error) {
+	encoded := []*jsonNode{}
+	for _, expression := range expressions {
+		node, err := encodeNode(expression)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, node)
+	}
+	return encoded, nil
+}
+
+func tokenOf(tok token.Token) *token.Token {
+	return &tok
+}
+
+// identifierNode and blockNode turn typed nil pointers into untyped nil
+// Nodes, so encodeNode can tell a missing child from an empty one.
+func identifierNode(identifier *Identifier) Node {
+	if identifier == nil {
+		return nil
+	}
+	return identifier
+}
+
+func blockNode(block *BlockStatement) Node {
+	if block == nil {
+		return nil
+	}
+	return block
+}
+
+func decodeNode(encoded *jsonNode) (Node, error) {
+	if encoded == nil {
+		return nil, nil
+	}
+
+	var tok token.Token
+	if encoded.Token != nil {
+		tok = *encoded.Token
+	}
+
+	switch encoded.Kind {
+	case "Program":
+		statements, err := decodeStatements(encoded.Statements)
+		if err != nil {
+			return nil, err
+		}
+		return &Program{Statements: statements}, nil
+	case "LetStatement":
+		name, err := decodeIdentifier(encoded.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := decodeExpression(encoded.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &LetStatement{Token: tok, Name: name, Value: value}, nil
+	case "ReturnStatement":
+		value, err := decodeExpression(encoded.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &ReturnStatement{Token: tok, ReturnValue: value}, nil
+	case "ExpressionStatement":
+		value, err := decodeExpression(encoded.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &ExpressionStatement{Token: tok, Expression: value}, nil
+	case "BlockStatement":
+		statements, err := decodeStatements(encoded.Statements)
+		if err != nil {
+			return nil, err
+		}
+		return &BlockStatement{Token: tok, Statements: statements}, nil
+	case "Identifier":
+		if encoded.String == nil {
+			return nil, missingValue(encoded)
+		}
+		return &Identifier{Token: tok, Value: *encoded.String}, nil
+	case "IntegerLiteral":
+		if encoded.Integer == nil {
+			return nil, missingValue(encoded)
+		}
+		return &IntegerLiteral{Token: tok, Value: *encoded.Integer}, nil
+	case "FloatLiteral":
+		if encoded.Float == nil {
+			return nil, missingValue(encoded)
+		}
+		return &FloatLiteral{Token: tok, Value: *encoded.Float}, nil
+	case "Boolean":
+		if encoded.Boolean == nil {
+			return nil, missingValue(encoded)
+		}
+		return &Boolean{Token: tok, Value: *encoded.Boolean}, nil
+	case "StringLiteral":
+		if encoded.String == nil {
+			return nil, missingValue(encoded)
+		}
+		return &StringLiteral{Token: tok, Value: *encoded.String}, nil
+	case "PrefixExpression":
+		right, err := decodeExpression(encoded.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &PrefixExpression{Token: tok, Operator: encoded.Operator, Right: right}, nil
+	case "InfixExpression":
+		left, err := decodeExpression(encoded.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := decodeExpression(encoded.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &InfixExpression{Token: tok, Left: left, Operator: encoded.Operator, Right: right}, nil
+	case "IfExpression":
+		condition, err := decodeExpression(encoded.Condition)
+		if err != nil {
+			return nil, err
+		}
+		consequence, err := decodeBlock(encoded.Consequence)
+		if err != nil {
+			return nil, err
+		}
+		alternative, err := decodeBlock(encoded.Alternative)
+		if err != nil {
+			return nil, err
+		}
+		return &IfExpression{Token: tok, Condition: condition, Consequence: consequence, Alternative: alternative}, nil
+	case "FunctionLiteral":
+		parameters, body, err := decodeFunction(encoded)
+		if err != nil {
+			return nil, err
+		}
+		return &FunctionLiteral{Token: tok, Parameters: parameters, Body: body}, nil
+	case "MacroLiteral":
+		parameters, body, err := decodeFunction(encoded)
+		if err != nil {
+			return nil, err
+		}
+		return &MacroLiteral{Token: tok, Parameters: parameters, Body: body}, nil
+	case "CallExpression":
+		function, err := decodeExpression(encoded.Function)
+		if err != nil {
+			return nil, err
+		}
+		arguments, err := decodeExpressions(encoded.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		return &CallExpression{Token: tok, Function: function, Arguments: arguments}, nil
+	case "ArrayLiteral":
+		elements, err := decodeExpressions(encoded.Elements)
+		if err != nil {
+			return nil, err
+		}
+		return &ArrayLiteral{Token: tok, Elements: elements}, nil
+	case "IndexExpression":
+		left, err := decodeExpression(encoded.Left)
+		if err != nil {
+			return nil, err
+		}
+		index, err := decodeExpression(encoded.Index)
+		if err != nil {
+			return nil, err
+		}
+		return &IndexExpression{Token: tok, Left: left, Index: index}, nil
+	case "ImportStatement":
+		value, err := decodeExpression(encoded.Value)
+		if err != nil {
+			return nil, err
+		}
+		path, ok := value.(*StringLiteral)
+		if value != nil && !ok {
+			return nil, fmt.Errorf("expected StringLiteral as import path, got %T", value)
+		}
+		name, err := decodeIdentifier(encoded.Name)
+		if err != nil {
+			return nil, err
+		}
+		return &ImportStatement{Token: tok, Path: path, Name: name}, nil
+	case "ImportExpression":
+		value, err := decodeExpression(encoded.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &ImportExpression{Token: tok, Path: value}, nil
+	case "MemberExpression":
+		left, err := decodeExpression(encoded.Left)
+		if err != nil {
+			return nil, err
+		}
+		member, err := decodeIdentifier(encoded.Name)
+		if err != nil {
+			return nil, err
+		}
+		return &MemberExpression{Token: tok, Left: left, Member: member}, nil
+	case "HashLiteral":
+		pairs := make(map[Expression]Expression)
+		for _, pair := range encoded.Pairs {
+			key, err := decodeExpression(pair.Key)
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeExpression(pair.Value)
+			if err != nil {
+				return nil, err
+			}
+			pairs[key] = value
+		}
+		return &HashLiteral{Token: tok, Pairs: pairs}, nil
+	default:
+		return nil, fmt.Errorf("unknown node kind %q", encoded.Kind)
+	}
+}
+
+func missingValue(encoded *jsonNode) error {
+	return fmt.Errorf("%s is missing its value", encoded.Kind)
+}
+
+func decodeExpression(encoded *jsonNode) (Expression, error) {
+	node, err := decodeNode(encoded)
+	if err != nil || node == nil {
+		return nil, err
+	}
+
+	expression, ok := node.(Expression)
+	if !ok {
+		return nil, fmt.Errorf("expected expression, got %s", encoded.Kind)
+	}
+
+	return expression, nil
+}
+
+func decodeStatements(encoded []*jsonNode) ([]Statement, error) {
+	statements := []Statement{}
+	for _, encodedStatement := range encoded {
+		node, err := decodeNode(encodedStatement)
+		if err != nil {
+			return nil, err
+		}
+
+		statement, ok := node.(Statement)
+		if !ok {
+			return nil, fmt.Errorf("expected statement, got %T", node)
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+func decodeExpressions(encoded []*jsonNode) ([]Expression, error) {
+	expressions := []Expression{}
+	for _, encodedExpression := range encoded {
+		expression, err := decodeExpression(encodedExpression)
+		if err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, expression)
+	}
+	return expressions, nil
+}
+
+func decodeIdentifier(encoded *jsonNode) (*Identifier, error) {
+	node, err := decodeNode(encoded)
+	if err != nil || node == nil {
+		return nil, err
+	}
+
+	identifier, ok := node.(*Identifier)
+	if !ok {
+		return nil, fmt.Errorf("expected Identifier, got %s", encoded.Kind)
+	}
+
+	return identifier, nil
+}
+
+func decodeBlock(encoded *jsonNode) (*BlockStatement, error) {
+	node, err := decodeNode(encoded)
+	if err != nil || node == nil {
+		return nil, err
+	}
+
+	block, ok := node.(*BlockStatement)
+	if !ok {
+		return nil, fmt.Errorf("expected BlockStatement, got %s", encoded.Kind)
+	}
+
+	return block, nil
+}
+
+func decodeFunction(encoded *jsonNode) ([]*Identifier, *BlockStatement, error) {
+	parameters := []*Identifier{}
+	for _, encodedParameter := range encoded.Parameters {
+		parameter, err := decodeIdentifier(encodedParameter)
+		if err != nil {
+			return nil, nil, err
+		}
+		parameters = append(parameters, parameter)
+	}
+
+	body, err := decodeBlock(encoded.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return parameters, body, nil
+}
@@ -0,0 +1,182 @@
+package ast
+
+import (
+	"monkey/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodeJSON(t *testing.T) {
+	node := &PrefixExpression{
+		Token:    token.Token{Type: token.MINUS, Literal: "-", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+		Operator: "-",
+		Right: &IntegerLiteral{
+			Token: token.Token{Type: token.INT, Literal: "5", Pos: token.Position{Offset: 1, Line: 1, Column: 2}},
+			Value: 5,
+		},
+	}
+
+	encoded, err := EncodeJSON(node)
+	if err != nil {
+		t.Fatalf("EncodeJSON returned error: %s", err)
+	}
+
+	expected := `{"kind":"PrefixExpression",` +
+		`"token":{"type":"-","literal":"-","pos":{"offset":0,"line":1,"column":1}},` +
+		`"operator":"-",` +
+		`"right":{"kind":"IntegerLiteral",` +
+		`"token":{"type":"INT","literal":"5","pos":{"offset":1,"line":1,"column":2}},` +
+		`"integer":5}}`
+
+	if string(encoded) != expected {
+		t.Errorf("wrong encoding.\ngot=%s\nwant=%s", encoded, expected)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	identifier := func(name string) *Identifier {
+		return &Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+	}
+	integer := func(value int64, offset int) *IntegerLiteral {
+		return &IntegerLiteral{Token: token.Token{Type: token.INT, Pos: token.Position{Offset: offset}}, Value: value}
+	}
+	block := func(statements ...Statement) *BlockStatement {
+		return &BlockStatement{Token: token.Token{Type: token.LBRACE, Literal: "{"}, Statements: statements}
+	}
+
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Name:  identifier("add"),
+				Value: &FunctionLiteral{
+					Token:      token.Token{Type: token.FUNCTION, Literal: "fn"},
+					Parameters: []*Identifier{identifier("a"), identifier("b")},
+					Body: block(&ReturnStatement{
+						Token:       token.Token{Type: token.RETURN, Literal: "return"},
+						ReturnValue: &InfixExpression{Left: identifier("a"), Operator: "+", Right: identifier("b")},
+					}),
+				},
+			},
+			&LetStatement{
+				Name: identifier("unless"),
+				Value: &MacroLiteral{
+					Parameters: []*Identifier{},
+					Body:       block(),
+				},
+			},
+			&ExpressionStatement{
+				Expression: &IfExpression{
+					Condition:   &PrefixExpression{Operator: "!", Right: &Boolean{Value: true}},
+					Consequence: block(&ExpressionStatement{Expression: &StringLiteral{Value: "yes"}}),
+				},
+			},
+			&ExpressionStatement{
+				Expression: &CallExpression{
+					Function: identifier("add"),
+					Arguments: []Expression{
+						&IndexExpression{
+							Left:  &ArrayLiteral{Elements: []Expression{integer(1, 0), integer(2, 0)}},
+							Index: integer(0, 0),
+						},
+						integer(9223372036854775807, 0),
+					},
+				},
+			},
+			&ExpressionStatement{
+				Expression: &HashLiteral{
+					Pairs: map[Expression]Expression{
+						integer(1, 10): &StringLiteral{Value: "one"},
+						integer(2, 20): &StringLiteral{Value: "two"},
+					},
+				},
+			},
+		},
+	}
+
+	encoded, err := EncodeJSON(program)
+	if err != nil {
+		t.Fatalf("EncodeJSON returned error: %s", err)
+	}
+
+	decoded, err := DecodeProgramJSON(encoded)
+	if err != nil {
+		t.Fatalf("DecodeProgramJSON returned error: %s", err)
+	}
+
+	if decoded.String() != program.String() {
+		t.Errorf("decoded program differs.\ngot=%s\nwant=%s", decoded.String(), program.String())
+	}
+
+	reencoded, err := EncodeJSON(decoded)
+	if err != nil {
+		t.Fatalf("EncodeJSON returned error: %s", err)
+	}
+
+	if string(reencoded) != string(encoded) {
+		t.Errorf("re-encoding differs.\ngot=%s\nwant=%s", reencoded, encoded)
+	}
+
+	call := decoded.Statements[3].(*ExpressionStatement).Expression.(*CallExpression)
+	if !reflect.DeepEqual(call.Arguments[1], integer(9223372036854775807, 0)) {
+		t.Errorf("large integer not preserved. got=%#v", call.Arguments[1])
+	}
+}
+
+func TestJSONLiteralFields(t *testing.T) {
+	tests := []struct {
+		node     Expression
+		expected string
+	}{
+		{&Boolean{Value: false}, `"boolean":false`},
+		{&StringLiteral{Value: ""}, `"string":""`},
+		{&Identifier{Value: "x"}, `"string":"x"`},
+		{&IntegerLiteral{Value: 0}, `"integer":0`},
+		{&FloatLiteral{Value: 1.5}, `"float":1.5`},
+	}
+
+	for _, test := range tests {
+		statement := &ExpressionStatement{Expression: test.node}
+
+		encoded, err := EncodeJSON(statement)
+		if err != nil {
+			t.Fatalf("EncodeJSON returned error: %s", err)
+		}
+		if !strings.Contains(string(encoded), test.expected) {
+			t.Errorf("encoding of %T lacks %s. got=%s", test.node, test.expected, encoded)
+		}
+
+		decoded, err := DecodeJSON(encoded)
+		if err != nil {
+			t.Fatalf("DecodeJSON returned error: %s", err)
+		}
+		if !reflect.DeepEqual(decoded, statement) {
+			t.Errorf("round trip of %T wrong. got=%#v", test.node, decoded)
+		}
+	}
+}
+
+func TestDecodeJSONErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"kind":"Bogus"}`, `unknown node kind "Bogus"`},
+		{`{"kind":"IntegerLiteral"}`, `IntegerLiteral is missing its value`},
+		{`{"kind":"Program","statements":[{"kind":"Identifier","string":"x"}]}`, `expected statement, got *ast.Identifier`},
+		{`{"kind":"PrefixExpression","right":{"kind":"BlockStatement"}}`, `expected expression, got BlockStatement`},
+	}
+
+	for _, test := range tests {
+		_, err := DecodeJSON([]byte(test.input))
+		if err == nil {
+			t.Errorf("expected error for %s", test.input)
+			continue
+		}
+
+		if err.Error() != test.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", test.expected, err.Error())
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package ast
+
+import (
+	"monkey/token"
+	"sort"
+)
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order: It starts by calling
+// v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor
+// w for each of the non-nil children of node, followed by a call of
+// w.Visit(nil).
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch node := node.(type) {
+	case *Program:
+		walkStatements(v, node.Statements)
+	case *LetStatement:
+		if node.Name != nil {
+			Walk(v, node.Name)
+		}
+		if node.Value != nil {
+			Walk(v, node.Value)
+		}
+	case *ReturnStatement:
+		if node.ReturnValue != nil {
+			Walk(v, node.ReturnValue)
+		}
+	case *ImportStatement:
+		if node.Path != nil {
+			Walk(v, node.Path)
+		}
+		if node.Name != nil {
+			Walk(v, node.Name)
+		}
+	case *ExpressionStatement:
+		if node.Expression != nil {
+			Walk(v, node.Expression)
+		}
+	case *BlockStatement:
+		walkStatements(v, node.Statements)
+	case *PrefixExpression:
+		if node.Right != nil {
+			Walk(v, node.Right)
+		}
+	case *InfixExpression:
+		if node.Left != nil {
+			Walk(v, node.Left)
+		}
+		if node.Right != nil {
+			Walk(v, node.Right)
+		}
+	case *IfExpression:
+		if node.Condition != nil {
+			Walk(v, node.Condition)
+		}
+		if node.Consequence != nil {
+			Walk(v, node.Consequence)
+		}
+		if node.Alternative != nil {
+			Walk(v, node.Alternative)
+		}
+	case *FunctionLiteral:
+		walkIdentifiers(v, node.Parameters)
+		if node.Body != nil {
+			Walk(v, node.Body)
+		}
+	case *MacroLiteral:
+		walkIdentifiers(v, node.Parameters)
+		if node.Body != nil {
+			Walk(v, node.Body)
+		}
+	case *CallExpression:
+		if node.Function != nil {
+			Walk(v, node.Function)
+		}
+		walkExpressions(v, node.Arguments)
+	case *ArrayLiteral:
+		walkExpressions(v, node.Elements)
+	case *IndexExpression:
+		if node.Left != nil {
+			Walk(v, node.Left)
+		}
+		if node.Index != nil {
+			Walk(v, node.Index)
+		}
+	case *MemberExpression:
+		if node.Left != nil {
+			Walk(v, node.Left)
+		}
+		if node.Member != nil {
+			Walk(v, node.Member)
+		}
+	case *ImportExpression:
+		if node.Path != nil {
+			Walk(v, node.Path)
+		}
+	case *HashLiteral:
+		for _, key := range SortedKeys(node) {
+			Walk(v, key)
+			if value := node.Pairs[key]; value != nil {
+				Walk(v, value)
+			}
+		}
+	case *Identifier, *IntegerLiteral, *FloatLiteral, *Boolean, *StringLiteral:
+		// Leaf nodes have no children.
+	}
+
+	v.Visit(nil)
+}
+
+func walkStatements(v Visitor, statements []Statement) {
+	for _, statement := range statements {
+		if statement != nil {
+			Walk(v, statement)
+		}
+	}
+}
+
+func walkExpressions(v Visitor, expressions []Expression) {
+	for _, expression := range expressions {
+		if expression != nil {
+			Walk(v, expression)
+		}
+	}
+}
+
+func walkIdentifiers(v Visitor, identifiers []*Identifier) {
+	for _, identifier := range identifiers {
+		if identifier != nil {
+			Walk(v, identifier)
+		}
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order: It starts by calling
+// f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the non-nil children of node, followed by a
+// call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// SortedKeys returns the keys of a hash literal in source order, so that
+// traversals and printers do not depend on Go's map iteration. Keys
+// without a source position, e.g. those created by macros, are ordered
+// by their String() representation.
+func SortedKeys(hash *HashLiteral) []Expression {
+	keys := make([]Expression, 0, len(hash.Pairs))
+	for key := range hash.Pairs {
+		if key != nil {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		left, right := Pos(keys[i]), Pos(keys[j])
+		if left.Offset != right.Offset {
+			return left.Offset < right.Offset
+		}
+		return keys[i].String() < keys[j].String()
+	})
+
+	return keys
+}
+
+// Pos returns the source position of the token a node was created from.
+// Nodes that were not produced by the parser report the zero Position.
+func Pos(node Node) token.Position {
+	switch node := node.(type) {
+	case *Program:
+		if len(node.Statements) > 0 {
+			return Pos(node.Statements[0])
+		}
+	case *LetStatement:
+		return node.Token.Pos
+	case *ReturnStatement:
+		return node.Token.Pos
+	case *ExpressionStatement:
+		return node.Token.Pos
+	case *BlockStatement:
+		return node.Token.Pos
+	case *Identifier:
+		return node.Token.Pos
+	case *IntegerLiteral:
+		return node.Token.Pos
+	case *FloatLiteral:
+		return node.Token.Pos
+	case *Boolean:
+		return node.Token.Pos
+	case *StringLiteral:
+		return node.Token.Pos
+	case *PrefixExpression:
+		return node.Token.Pos
+	case *InfixExpression:
+		return node.Token.Pos
+	case *IfExpression:
+		return node.Token.Pos
+	case *FunctionLiteral:
+		return node.Token.Pos
+	case *MacroLiteral:
+		return node.Token.Pos
+	case *CallExpression:
+		return node.Token.Pos
+	case *ArrayLiteral:
+		return node.Token.Pos
+	case *IndexExpression:
+		return node.Token.Pos
+	case *MemberExpression:
+		return node.Token.Pos
+	case *ImportStatement:
+		return node.Token.Pos
+	case *ImportExpression:
+		return node.Token.Pos
+	case *HashLiteral:
+		return node.Token.Pos
+	}
+	return token.Position{}
+}
@@ -0,0 +1,167 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type recordingVisitor struct {
+	events *[]string
+}
+
+func (visitor recordingVisitor) Visit(node Node) Visitor {
+	if node == nil {
+		*visitor.events = append(*visitor.events, "post")
+		return nil
+	}
+	*visitor.events = append(*visitor.events, fmt.Sprintf("%T", node))
+	return visitor
+}
+
+func TestWalk(t *testing.T) {
+	identifier := func(name string) *Identifier { return &Identifier{Value: name} }
+	integer := func(value int64) *IntegerLiteral { return &IntegerLiteral{Value: value} }
+
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Name: identifier("add"),
+				Value: &FunctionLiteral{
+					Parameters: []*Identifier{identifier("a")},
+					Body: &BlockStatement{
+						Statements: []Statement{
+							&ReturnStatement{
+								ReturnValue: &InfixExpression{
+									Left:     identifier("a"),
+									Operator: "+",
+									Right:    integer(1),
+								},
+							},
+						},
+					},
+				},
+			},
+			&ExpressionStatement{
+				Expression: &CallExpression{
+					Function:  identifier("add"),
+					Arguments: []Expression{&StringLiteral{Value: "x"}},
+				},
+			},
+		},
+	}
+
+	events := []string{}
+	Walk(recordingVisitor{events: &events}, program)
+
+	expected := []string{
+		"*ast.Program",
+		"*ast.LetStatement",
+		"*ast.Identifier", "post",
+		"*ast.FunctionLiteral",
+		"*ast.Identifier", "post",
+		"*ast.BlockStatement",
+		"*ast.ReturnStatement",
+		"*ast.InfixExpression",
+		"*ast.Identifier", "post",
+		"*ast.IntegerLiteral", "post",
+		"post",
+		"post",
+		"post",
+		"post",
+		"post",
+		"*ast.ExpressionStatement",
+		"*ast.CallExpression",
+		"*ast.Identifier", "post",
+		"*ast.StringLiteral", "post",
+		"post",
+		"post",
+		"post",
+	}
+
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("wrong visit order.\ngot=%v\nwant=%v", events, expected)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	one := func() Expression { return &IntegerLiteral{Value: 1} }
+
+	tests := []struct {
+		input    Node
+		expected int
+	}{
+		{one(), 1},
+		{&PrefixExpression{Operator: "-", Right: one()}, 2},
+		{&IndexExpression{Left: &ArrayLiteral{Elements: []Expression{one(), one()}}, Index: one()}, 5},
+		{
+			&IfExpression{
+				Condition: &Boolean{Value: true},
+				Consequence: &BlockStatement{
+					Statements: []Statement{&ExpressionStatement{Expression: one()}},
+				},
+				Alternative: &BlockStatement{
+					Statements: []Statement{&ExpressionStatement{Expression: one()}},
+				},
+			},
+			8,
+		},
+		{
+			&MacroLiteral{
+				Parameters: []*Identifier{{Value: "x"}},
+				Body: &BlockStatement{
+					Statements: []Statement{&ExpressionStatement{Expression: one()}},
+				},
+			},
+			5,
+		},
+		{&HashLiteral{Pairs: map[Expression]Expression{one(): one(), &StringLiteral{Value: "a"}: one()}}, 5},
+		{&LetStatement{Name: &Identifier{Value: "x"}}, 2},
+	}
+
+	for _, test := range tests {
+		count := 0
+		Inspect(test.input, func(node Node) bool {
+			if node != nil {
+				count++
+			}
+			return true
+		})
+
+		if count != test.expected {
+			t.Errorf("wrong number of nodes for %T. got=%d, want=%d", test.input, count, test.expected)
+		}
+	}
+}
+
+func TestInspectPrunesSubtrees(t *testing.T) {
+	program := &Program{
+		Statements: []Statement{
+			&ExpressionStatement{
+				Expression: &FunctionLiteral{
+					Body: &BlockStatement{
+						Statements: []Statement{
+							&ExpressionStatement{Expression: &Identifier{Value: "hidden"}},
+						},
+					},
+				},
+			},
+			&ExpressionStatement{Expression: &Identifier{Value: "visible"}},
+		},
+	}
+
+	names := []string{}
+	Inspect(program, func(node Node) bool {
+		switch node := node.(type) {
+		case *FunctionLiteral:
+			return false
+		case *Identifier:
+			names = append(names, node.Value)
+		}
+		return true
+	})
+
+	if !reflect.DeepEqual(names, []string{"visible"}) {
+		t.Errorf("wrong identifiers visited. got=%v", names)
+	}
+}
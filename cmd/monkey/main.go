@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+
+	"monkey"
+	"monkey/ast"
+	"monkey/evaluator"
+	"monkey/format"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"monkey/repl"
+)
+
+func main() {
+	args := os.Args[1:]
+
+	// Scripts may only read files unless writing is allowed explicitly.
+	fsys := evaluator.ReadOnlyFS(evaluator.DirFS("."))
+	if len(args) > 0 && args[0] == "--allow-write" {
+		fsys = evaluator.DirFS(".")
+		args = args[1:]
+	}
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "ast":
+			os.Exit(astCommand(args[1:], os.Stdout, os.Stderr))
+		case "fmt":
+			os.Exit(fmtCommand(args[1:], os.Stdin, os.Stdout, os.Stderr))
+		case "run":
+			os.Exit(runCommand(args[1:], fsys, os.Stdout, os.Stderr))
+		case "-e":
+			os.Exit(evalCommand(args[1:], fsys, os.Stdout, os.Stderr))
+		default:
+			// Lets scripts start with "#!/usr/bin/env monkey".
+			if _, err := os.Stat(args[0]); err == nil {
+				os.Exit(runCommand(args, fsys, os.Stdout, os.Stderr))
+			}
+		}
+	}
+
+	current, err := user.Current()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Hello, %s! This is the Monkey programming language!\n", current.Username)
+	fmt.Printf("Feel free to type in commands.\n")
+	repl.StartWithFS(os.Stdin, os.Stdout, fsys)
+}
+
+func astCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("ast", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print the syntax tree as JSON")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: monkey ast [--json] file.mk\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	program, ok := parseFile(flags.Arg(0), stderr)
+	if !ok {
+		return 1
+	}
+
+	if !*asJSON {
+		fmt.Fprintln(stdout, program.String())
+		return 0
+	}
+
+	encoded, err := ast.EncodeJSON(program)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s\n", err)
+		return 1
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, encoded, "", "  "); err != nil {
+		fmt.Fprintf(stderr, "%s\n", err)
+		return 1
+	}
+	indented.WriteString("\n")
+	indented.WriteTo(stdout)
+
+	return 0
+}
+
+func fmtCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("w", false, "write result to (source) file instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: monkey fmt [-w] [files...]\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		if *write {
+			fmt.Fprintf(stderr, "cannot use -w with standard input\n")
+			return 2
+		}
+
+		source, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s\n", err)
+			return 1
+		}
+
+		formatted, err := format.Source(source)
+		if err != nil {
+			fmt.Fprintf(stderr, "<standard input>: %s\n", err)
+			return 1
+		}
+
+		stdout.Write(formatted)
+		return 0
+	}
+
+	status := 0
+	for _, filename := range flags.Args() {
+		if err := formatFile(filename, *write, stdout); err != nil {
+			fmt.Fprintf(stderr, "%s: %s\n", filename, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+func formatFile(filename string, write bool, stdout io.Writer) error {
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	formatted, err := format.Source(source)
+	if err != nil {
+		return err
+	}
+
+	if !write {
+		_, err = stdout.Write(formatted)
+		return err
+	}
+
+	if bytes.Equal(source, formatted) {
+		return nil
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, formatted, info.Mode().Perm())
+}
+
+func runCommand(args []string, fsys object.FileSystem, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintf(stderr, "usage: monkey run file.mk [args...]\n")
+		return 2
+	}
+
+	source, err := os.ReadFile(args[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "%s\n", err)
+		return 1
+	}
+
+	// Imports are relative to the script, wherever monkey runs.
+	dir := path.Dir(filepath.ToSlash(filepath.Clean(args[0])))
+
+	return execute(args[0], string(source), args[1:], false, fsys, dir, stdout, stderr)
+}
+
+func evalCommand(args []string, fsys object.FileSystem, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintf(stderr, "usage: monkey -e 'expression' [args...]\n")
+		return 2
+	}
+
+	return execute("-e", args[0], args[1:], true, fsys, ".", stdout, stderr)
+}
+
+// execute evaluates source with the script arguments bound to args and
+// access to the files of fsys, importing relative to its directory dir,
+// and returns the process exit code.
+func execute(name, source string, args []string, printResult bool, fsys object.FileSystem, dir string, stdout, stderr io.Writer) int {
+	elements := []object.Object{}
+	for _, arg := range args {
+		elements = append(elements, &object.String{Value: arg})
+	}
+
+	interpreter := monkey.New(
+		monkey.WithGlobal("args", &object.Array{Elements: elements}),
+		monkey.WithStdout(stdout),
+		monkey.WithStderr(stderr),
+		monkey.WithFS(fsys),
+		monkey.WithImportDir(dir),
+	)
+
+	evaluated, err := interpreter.Run(context.Background(), source)
+	switch err := err.(type) {
+	case nil:
+	case *monkey.ParseError:
+		for _, msg := range err.Errors {
+			fmt.Fprintf(stderr, "%s: %s\n", name, msg)
+		}
+		return 1
+	case *monkey.RuntimeError:
+		fmt.Fprintf(stderr, "%s: ERROR: %s\n", name, err)
+		return 1
+	default:
+		fmt.Fprintf(stderr, "%s: %s\n", name, err)
+		return 1
+	}
+
+	if printResult && evaluated != evaluator.NULL {
+		fmt.Fprintln(stdout, evaluated.Inspect())
+	}
+
+	return 0
+}
+
+func parseFile(filename string, stderr io.Writer) (*ast.Program, bool) {
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s\n", err)
+		return nil, false
+	}
+
+	p := parser.New(lexer.New(string(source)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		for _, msg := range p.Errors() {
+			fmt.Fprintf(stderr, "%s: %s\n", filename, msg)
+		}
+		return nil, false
+	}
+
+	return program, true
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"monkey/evaluator"
+	"monkey/object"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		source         string
+		args           []string
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{"1 + 2", nil, 0, "3\n", ""},
+		{"len(args)", []string{"a", "b"}, 0, "2\n", ""},
+		{`args[1] + "!"`, []string{"a", "b"}, 0, "b!\n", ""},
+		{"let x = 5;", nil, 0, "", ""},
+		{"if (false) { 1 }", nil, 0, "", ""},
+		{"let = 5;", nil, 1, "", "test: expected next token to be IDENT, got = instead\ntest: no prefix parse function for = found\n"},
+		{"1 + true", nil, 1, "", "test: ERROR: type mismatch: INTEGER + BOOLEAN\n"},
+		{"#!/usr/bin/env monkey\n5", nil, 0, "5\n", ""},
+		{`puts("a", 1); print("b", 2); eprint("oops")`, nil, 0, "a\n1\nb 2", "oops\n"},
+	}
+
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+
+		code := execute("test", test.source, test.args, true, nil, ".", &stdout, &stderr)
+
+		if code != test.expectedCode {
+			t.Errorf("wrong exit code for %q. expected=%d, got=%d", test.source, test.expectedCode, code)
+		}
+		if stdout.String() != test.expectedStdout {
+			t.Errorf("wrong stdout for %q. expected=%q, got=%q", test.source, test.expectedStdout, stdout.String())
+		}
+		if stderr.String() != test.expectedStderr {
+			t.Errorf("wrong stderr for %q. expected=%q, got=%q", test.source, test.expectedStderr, stderr.String())
+		}
+	}
+}
+
+func TestExecuteWriteAccess(t *testing.T) {
+	dir := t.TempDir()
+	source := `writeFile("out.txt", "x")`
+
+	tests := []struct {
+		fsys           object.FileSystem
+		expectedCode   int
+		expectedStderr string
+	}{
+		{evaluator.ReadOnlyFS(evaluator.DirFS(dir)), 1, "test: ERROR: cannot write \"out.txt\": permission denied\n"},
+		{evaluator.DirFS(dir), 0, ""},
+	}
+
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+
+		code := execute("test", source, nil, true, test.fsys, ".", &stdout, &stderr)
+
+		if code != test.expectedCode {
+			t.Errorf("wrong exit code. expected=%d, got=%d", test.expectedCode, code)
+		}
+		if stderr.String() != test.expectedStderr {
+			t.Errorf("wrong stderr. expected=%q, got=%q", test.expectedStderr, stderr.String())
+		}
+	}
+}
+
+func TestRunCommandImports(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"sub/main.mk": `import "util.mk" as util; puts(util.answer)`,
+		"sub/util.mk": `let answer = 42;`,
+	}
+	for name, source := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+
+	var stdout, stderr bytes.Buffer
+	code := runCommand([]string{"sub/main.mk"}, evaluator.DirFS("."), &stdout, &stderr)
+
+	if code != 0 || stdout.String() != "42\n" {
+		t.Errorf("wrong result of running sub/main.mk. code=%d, stdout=%q, stderr=%q", code, stdout.String(), stderr.String())
+	}
+}
@@ -2,12 +2,16 @@ package evaluator
 
 import (
 	"fmt"
+	"io"
 	"monkey/object"
+	"sort"
+	"strings"
+	"unicode/utf8"
 )
 
 var builtins = map[string]*object.Builtin{
 	"len": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
 			}
@@ -16,7 +20,9 @@ var builtins = map[string]*object.Builtin{
 			case *object.Array:
 				return &object.Integer{Value: int64(len(arg.Elements))}
 			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+			case *object.Hash:
+				return &object.Integer{Value: int64(len(arg.Pairs))}
 			default:
 				return newError("argument to `len` not supported, got %s",
 					args[0].Type())
@@ -25,7 +31,7 @@ var builtins = map[string]*object.Builtin{
 	},
 
 	"first": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1",
 					len(args))
@@ -46,7 +52,7 @@ var builtins = map[string]*object.Builtin{
 	},
 
 	"last": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1",
 					len(args))
@@ -68,7 +74,7 @@ var builtins = map[string]*object.Builtin{
 	},
 
 	"rest": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1",
 					len(args))
@@ -92,7 +98,7 @@ var builtins = map[string]*object.Builtin{
 	},
 
 	"push": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
 			if len(args) != 2 {
 				return newError("wrong number of arguments. got=%d, want=2",
 					len(args))
@@ -115,12 +121,143 @@ var builtins = map[string]*object.Builtin{
 	},
 
 	"puts": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
 			for _, arg := range args {
-				fmt.Println(arg.Inspect())
+				if _, err := fmt.Fprintln(ctx.Stdout, arg.Inspect()); err != nil {
+					return newError("%s", err)
+				}
 			}
 
 			return NULL
 		},
 	},
+
+	"print": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if _, err := io.WriteString(ctx.Stdout, joinArgs(args)); err != nil {
+				return newError("%s", err)
+			}
+
+			return NULL
+		},
+	},
+
+	"eprint": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if _, err := io.WriteString(ctx.Stderr, joinArgs(args)+"\n"); err != nil {
+				return newError("%s", err)
+			}
+
+			return NULL
+		},
+	},
+
+	"readLine": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. got=%d, want=0",
+					len(args))
+			}
+
+			line, err := ctx.Stdin.ReadString('\n')
+			if err == io.EOF && line == "" {
+				return NULL
+			}
+			if err != nil && err != io.EOF {
+				return newError("%s", err)
+			}
+
+			line = strings.TrimSuffix(line, "\n")
+			return &object.String{Value: strings.TrimSuffix(line, "\r")}
+		},
+	},
+
+	"readAll": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. got=%d, want=0",
+					len(args))
+			}
+
+			contents, err := io.ReadAll(ctx.Stdin)
+			if err != nil {
+				return newError("%s", err)
+			}
+
+			return &object.String{Value: string(contents)}
+		},
+	},
+}
+
+func init() {
+	groups := []map[string]*object.Builtin{
+		stringBuiltins,
+		collectionBuiltins,
+		hashBuiltins,
+		typeBuiltins,
+		jsonBuiltins,
+		randomBuiltins,
+		timeBuiltins,
+		fileBuiltins,
+		regexBuiltins,
+	}
+
+	for _, group := range groups {
+		for name, builtin := range group {
+			if _, ok := builtins[name]; ok {
+				panic("builtin defined twice: " + name)
+			}
+			builtins[name] = builtin
+		}
+	}
+}
+
+// checkArguments returns an error unless args has exactly the given types,
+// in the same words as the builtins checking their arguments by hand. A
+// builtin is accepted where a FUNCTION is expected.
+func checkArguments(name string, args []object.Object, types ...object.ObjectType) *object.Error {
+	if len(args) != len(types) {
+		return newError("wrong number of arguments. got=%d, want=%d",
+			len(args), len(types))
+	}
+
+	for i, typ := range types {
+		if args[i].Type() == typ || typ == object.FUNCTION_OBJ && args[i].Type() == object.BUILTIN_OBJ {
+			continue
+		}
+		if len(types) == 1 {
+			return newError("argument to `%s` must be %s, got %s", name, typ, args[i].Type())
+		}
+		return newError("argument %d to `%s` must be %s, got %s", i+1, name, typ, args[i].Type())
+	}
+
+	return nil
+}
+
+// joinArgs joins the printed forms of args with spaces.
+func joinArgs(args []object.Object) string {
+	printed := make([]string, len(args))
+	for i, arg := range args {
+		printed[i] = arg.Inspect()
+	}
+	return strings.Join(printed, " ")
+}
+
+// builtinModules holds the namespaces of builtins, such as math.
+var builtinModules = map[string]*object.Module{
+	"math": mathModule,
+}
+
+// BuiltinNames returns the names of all builtin functions and modules in
+// sorted order.
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins)+len(builtinModules))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	for name := range builtinModules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
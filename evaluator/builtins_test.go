@@ -0,0 +1,380 @@
+package evaluator
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"monkey/object"
+	"strings"
+	"testing"
+	"time"
+)
+
+type builtinTest struct {
+	input    string
+	expected string
+}
+
+// testBuiltins evaluates the input of each test and compares the Repr of
+// the result with the expected string.
+func testBuiltins(t *testing.T, tests []builtinTest) {
+	t.Helper()
+
+	for _, test := range tests {
+		evaluated := testEval(test.input)
+		if evaluated == nil {
+			t.Errorf("no result for %s", test.input)
+			continue
+		}
+
+		if actual := object.Repr(evaluated); actual != test.expected {
+			t.Errorf("wrong result for %s.\nexpected=%s\ngot=     %s", test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestStringBuiltins(t *testing.T) {
+	testBuiltins(t, []builtinTest{
+		{`len("héllo")`, `5`},
+		{`split("a,b,,c", ",")`, `["a", "b", "", "c"]`},
+		{`split("日本語", "")`, `["日", "本", "語"]`},
+		{`split("abc", 1)`, "ERROR: argument 2 to `split` must be STRING, got INTEGER"},
+		{`join(["a", "b", "c"], ", ")`, `"a, b, c"`},
+		{`join([], "-")`, `""`},
+		{`join(["a", 1], "-")`, "ERROR: argument 1 to `join` must be ARRAY of STRING, got INTEGER at index 1"},
+		{`trim("  spaced out ")`, `"spaced out"`},
+		{`upper("héllo")`, `"HÉLLO"`},
+		{`lower("ÀBC")`, `"àbc"`},
+		{`lower()`, "ERROR: wrong number of arguments. got=0, want=1"},
+		{`lower(1)`, "ERROR: argument to `lower` must be STRING, got INTEGER"},
+		{`replace("a-b-c", "-", "+")`, `"a+b+c"`},
+		{`contains("monkey", "key")`, `true`},
+		{`contains("monkey", "donkey")`, `false`},
+		{`startsWith("monkey", "mon")`, `true`},
+		{`endsWith("monkey", "mon")`, `false`},
+		{`indexOf("héllo", "l")`, `2`},
+		{`indexOf("hello", "z")`, `-1`},
+		{`substr("héllo", 1, 3)`, `"éll"`},
+		{`substr("héllo", 3)`, `"lo"`},
+		{`substr("héllo", 2, 100)`, `"llo"`},
+		{`substr("héllo", 5)`, `""`},
+		{`substr("abc", 1, 9223372036854775807)`, `"bc"`},
+		{`substr("héllo", 6)`, "ERROR: start 6 out of range for string of length 5"},
+		{`substr("héllo", 1, -1)`, "ERROR: negative length -1 in call to `substr`"},
+		{`repeat("ab", 3)`, `"ababab"`},
+		{`repeat("ab", 0)`, `""`},
+		{`repeat("ab", -1)`, "ERROR: negative count -1 in call to `repeat`"},
+		{`repeat("ab", 9223372036854775807)`, "ERROR: result of `repeat` too long"},
+		{`format("%s is %d years old", "Ann", 31)`, `"Ann is 31 years old"`},
+		{`format("%v and %s", ["a"], ["a"])`, `"[\"a\"] and [a]"`},
+		{`format("100%%")`, `"100%"`},
+		{`format("%d", "x")`, "ERROR: %d in format needs INTEGER, got STRING"},
+		{`format("%d %d", 1)`, "ERROR: missing argument for %d in format"},
+		{`format("%d", 1, 2)`, "ERROR: too many arguments for format. got=2, want=1"},
+		{`format("%x", 1)`, "ERROR: unknown verb %x in format"},
+		{`format("50%")`, "ERROR: format ends with %"},
+	})
+}
+
+func TestIOBuiltins(t *testing.T) {
+	tests := []struct {
+		input          string
+		stdin          string
+		expected       string
+		expectedStdout string
+		expectedStderr string
+	}{
+		{`puts("a", [1, "b"])`, "", "null", "a\n[1, b]\n", ""},
+		{`print("a", 1); print("b")`, "", "null", "a 1b", ""},
+		{`eprint("warning:", 42)`, "", "null", "", "warning: 42\n"},
+		{`[readLine(), readLine(), readLine()]`, "one\r\ntwo", `["one", "two", null]`, "", ""},
+		{`let first = readLine(); [first, readAll()]`, "one\ntwo\nthree\n", `["one", "two\nthree\n"]`, "", ""},
+		{`readAll()`, "", `""`, "", ""},
+		{`readLine(1)`, "", "ERROR: wrong number of arguments. got=1, want=0", "", ""},
+	}
+
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+
+		runtime := NewRuntime()
+		runtime.Stdout = &stdout
+		runtime.Stderr = &stderr
+		runtime.Stdin = strings.NewReader(test.stdin)
+
+		evaluated := runtime.Eval(context.Background(), testParseProgram(test.input), object.NewEnvironment())
+
+		if actual := object.Repr(evaluated); actual != test.expected {
+			t.Errorf("wrong result for %s. expected=%s, got=%s", test.input, test.expected, actual)
+		}
+		if stdout.String() != test.expectedStdout {
+			t.Errorf("wrong stdout for %s. expected=%q, got=%q", test.input, test.expectedStdout, stdout.String())
+		}
+		if stderr.String() != test.expectedStderr {
+			t.Errorf("wrong stderr for %s. expected=%q, got=%q", test.input, test.expectedStderr, stderr.String())
+		}
+	}
+}
+
+func TestCollectionBuiltins(t *testing.T) {
+	testBuiltins(t, []builtinTest{
+		{`map([1, 2, 3], fn(x) { x * 2 })`, `[2, 4, 6]`},
+		{`map([], fn(x) { x })`, `[]`},
+		{`map(["a", "bc"], len)`, `[1, 2]`},
+		{`map([1, 2], fn(x) { x + "a" })`, "ERROR: type mismatch: INTEGER + STRING"},
+		{`map([1], fn(x, y) { x })`, "ERROR: wrong number of arguments. got=1, want=2"},
+		{`map([1], 2)`, "ERROR: argument 2 to `map` must be FUNCTION, got INTEGER"},
+		{`filter([1, 2, 3, 4], fn(x) { x > 2 })`, `[3, 4]`},
+		{`reduce([1, 2, 3, 4], 0, fn(sum, x) { sum + x })`, `10`},
+		{`reduce([], "empty", fn(acc, x) { x })`, `"empty"`},
+		{`reduce(range(100000), 0, fn(sum, x) { sum + x })`, `4999950000`},
+		{`each([1, 2], fn(x) { x })`, `null`},
+		{`each([1, 2], fn(x) { x + true })`, "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{`find([1, 2, 3, 4], fn(x) { x > 2 })`, `3`},
+		{`find([1, 2], fn(x) { x > 2 })`, `null`},
+		{`any([1, 2, 3], fn(x) { x == 2 })`, `true`},
+		{`any([], fn(x) { true })`, `false`},
+		{`all([1, 2, 3], fn(x) { x > 0 })`, `true`},
+		{`all([1, -2, 3], fn(x) { x > 0 })`, `false`},
+		{`sort([3, 1, 2])`, `[1, 2, 3]`},
+		{`sort(["b", "c", "a"])`, `["a", "b", "c"]`},
+		{`sort([3, 1, 2], fn(a, b) { a > b })`, `[3, 2, 1]`},
+		{`let people = [{"n": "b", "a": 2}, {"n": "a", "a": 1}, {"n": "c", "a": 2}]; map(sort(people, fn(x, y) { x["a"] < y["a"] }), fn(p) { p["n"] })`, `["a", "b", "c"]`},
+		{`sort([1, "a"])`, "ERROR: cannot compare STRING with INTEGER, pass a comparison function to `sort`"},
+		{`sort([2, 1], fn(a, b) { a + "x" })`, "ERROR: type mismatch: INTEGER + STRING"},
+		{`let a = [3, 1]; sort(a); a`, `[3, 1]`},
+		{`reverse([1, 2, 3])`, `[3, 2, 1]`},
+		{`reverse("héllo")`, `"olléh"`},
+		{`zip([1, 2, 3], ["a", "b"])`, `[[1, "a"], [2, "b"]]`},
+		{`zip([1], [2], [3])`, `[[1, 2, 3]]`},
+		{`zip([1], 2)`, "ERROR: argument 2 to `zip` must be ARRAY, got INTEGER"},
+		{`range(4)`, `[0, 1, 2, 3]`},
+		{`range(2, 5)`, `[2, 3, 4]`},
+		{`range(0, 10, 3)`, `[0, 3, 6, 9]`},
+		{`range(5, 0, -2)`, `[5, 3, 1]`},
+		{`range(5, 0)`, `[]`},
+		{`range(0, 1, 0)`, "ERROR: step of `range` must not be zero"},
+		{`range(9223372036854775807)`, "ERROR: result of `range` too long"},
+		{`range(0, 9223372036854775807, 2)`, "ERROR: result of `range` too long"},
+		{`range(-9223372036854775807 - 1, 9223372036854775807)`, "ERROR: result of `range` too long"},
+		{`range(9223372036854775800, 9223372036854775807, 3)`, `[9223372036854775800, 9223372036854775803, 9223372036854775806]`},
+		{`range(0, -9223372036854775807 - 1, -9223372036854775807 - 1)`, `[0]`},
+		{`flatten([1, [2, 3], [[4]], []])`, `[1, 2, 3, [4]]`},
+		{`uniq([1, 2, 1, "a", "a", true])`, `[1, 2, "a", true]`},
+		{`uniq([[1]])`, "ERROR: unusable as hash key: ARRAY"},
+		{`slice([1, 2, 3, 4], 1, 3)`, `[2, 3]`},
+		{`slice([1, 2, 3, 4], 2)`, `[3, 4]`},
+		{`slice([1, 2, 3, 4], 1, 100)`, `[2, 3, 4]`},
+		{`slice([1, 2], 3)`, "ERROR: slice [3:2] out of range for array of length 2"},
+		{`slice([1, 2], 2, 1)`, "ERROR: slice [2:1] out of range for array of length 2"},
+	})
+}
+
+func TestTypeBuiltins(t *testing.T) {
+	testBuiltins(t, []builtinTest{
+		{`type(1)`, `"INTEGER"`},
+		{`type(1.5)`, `"FLOAT"`},
+		{`type("a")`, `"STRING"`},
+		{`type([])`, `"ARRAY"`},
+		{`type({})`, `"HASH"`},
+		{`type(if (false) { 1 })`, `"NULL"`},
+		{`type(fn() {})`, `"FUNCTION"`},
+		{`type(len)`, `"BUILTIN"`},
+		{`str(12)`, `"12"`},
+		{`str("a")`, `"a"`},
+		{`str([1, "a"])`, `"[1, a]"`},
+		{`str(2.0)`, `"2.0"`},
+		{`int("42")`, `42`},
+		{`int(" -7 ")`, `-7`},
+		{`int(3.9)`, `3`},
+		{`int(-3.9)`, `-3`},
+		{`int(true)`, `1`},
+		{`int("4x")`, `ERROR: cannot parse "4x" as INTEGER`},
+		{`int("99999999999999999999")`, `ERROR: cannot parse "99999999999999999999" as INTEGER`},
+		{`int(1e300)`, `ERROR: cannot convert 1e+300 to INTEGER`},
+		{`int([])`, "ERROR: argument to `int` not supported, got ARRAY"},
+		{`float(2)`, `2.0`},
+		{`float("0.25")`, `0.25`},
+		{`float("x")`, `ERROR: cannot parse "x" as FLOAT`},
+		{`bool(0)`, `true`},
+		{`bool(if (false) { 1 })`, `false`},
+		{`bool(false)`, `false`},
+		{`isNull(if (false) { 1 })`, `true`},
+		{`isNull(0)`, `false`},
+		{`isNumber(1)`, `true`},
+		{`isNumber(1.5)`, `true`},
+		{`isNumber("1")`, `false`},
+		{`isFunction(fn() {})`, `true`},
+		{`isFunction(len)`, `true`},
+		{`isString("a")`, `true`},
+		{`isArray({})`, `false`},
+		{`isHash({})`, `true`},
+		{`isNull()`, "ERROR: wrong number of arguments. got=0, want=1"},
+	})
+}
+
+func TestMathModule(t *testing.T) {
+	testBuiltins(t, []builtinTest{
+		{`math.pi`, `3.141592653589793`},
+		{`math.e`, `2.718281828459045`},
+		{`math.abs(-3)`, `3`},
+		{`math.abs(-2.5)`, `2.5`},
+		{`math.abs(-9223372036854775807 - 1)`, "ERROR: integer overflow in `math.abs`"},
+		{`math.min(3, 1, 2)`, `1`},
+		{`math.max(3, 1.5)`, `3.0`},
+		{`math.min()`, "ERROR: wrong number of arguments. got=0, want at least 1"},
+		{`math.max(1, "2")`, "ERROR: argument 2 to `math.max` must be INTEGER or FLOAT, got STRING"},
+		{`math.pow(2, 10)`, `1024`},
+		{`math.pow(2, -1)`, `0.5`},
+		{`math.pow(2.0, 0.5)`, `1.4142135623730951`},
+		{`math.pow(2, 63)`, "ERROR: integer overflow in `math.pow`"},
+		{`math.pow(-8, 1.0 / 3)`, "ERROR: arguments to `math.pow` out of domain, got [-8 0.3333333333333333]"},
+		{`math.pow(0, -1)`, "ERROR: result of `math.pow` out of range"},
+		{`math.sqrt(16)`, `4.0`},
+		{`math.sqrt(-1)`, "ERROR: argument to `math.sqrt` out of domain, got -1"},
+		{`math.sqrt("4")`, "ERROR: argument to `math.sqrt` must be INTEGER or FLOAT, got STRING"},
+		{`math.floor(2.7)`, `2`},
+		{`math.floor(-2.5)`, `-3`},
+		{`math.ceil(2.1)`, `3`},
+		{`math.round(2.5)`, `3`},
+		{`math.round(7)`, `7`},
+		{`math.floor(1e300)`, "ERROR: cannot convert 1e+300 to INTEGER"},
+		{`math.log(math.e)`, `1.0`},
+		{`math.log(8, 2)`, `3.0`},
+		{`math.log(0)`, "ERROR: argument to `math.log` out of domain, got 0"},
+		{`math.log(8, 1)`, "ERROR: base of `math.log` out of domain, got 1"},
+		{`math.sin(0)`, `0.0`},
+		{`math.cos(0)`, `1.0`},
+		{`math.atan(1) * 4`, `3.141592653589793`},
+		{`math.atan(-1, -1)`, `-2.356194490192345`},
+		{`math.asin(2)`, "ERROR: argument to `math.asin` out of domain, got 2"},
+		{`math.gcd(-12, 18)`, `6`},
+		{`math.gcd(0, 0)`, `0`},
+		{`math.divmod(7, 2)`, `[3, 1]`},
+		{`math.divmod(-7, 2)`, `[-4, 1]`},
+		{`math.divmod(7, -2)`, `[-4, -1]`},
+		{`math.divmod(1, 0)`, "ERROR: division by zero"},
+		{`math.gcd(1.5, 2)`, "ERROR: argument 1 to `math.gcd` must be INTEGER, got FLOAT"},
+		{`let sqrt = math.sqrt; sqrt(4)`, `2.0`},
+	})
+}
+
+func TestRandomBuiltins(t *testing.T) {
+	testBuiltins(t, []builtinTest{
+		{`let r = random(); [r < 0, r < 1]`, `[false, true]`},
+		{`all(map(range(100), fn(i) { randInt(-2, 3) }), fn(n) { if (n < -2) { false } else { n < 3 } })`, `true`},
+		{`randInt(5, 6)`, `5`},
+		{`randInt(5, 5)`, "ERROR: range [5, 5) of `randInt` is empty or too large"},
+		{`randInt(-9223372036854775807, 9223372036854775807)`,
+			"ERROR: range [-9223372036854775807, 9223372036854775807) of `randInt` is empty or too large"},
+		{`sort(shuffle([3, 1, 2]))`, `[1, 2, 3]`},
+		{`let a = [1, 2, 3]; shuffle(a); a`, `[1, 2, 3]`},
+		{`choice(["only"])`, `"only"`},
+		{`choice([])`, "ERROR: argument to `choice` must not be empty"},
+		{`seed(1)`, `null`},
+		{`seed("1")`, "ERROR: argument to `seed` must be INTEGER, got STRING"},
+		{`random(1)`, "ERROR: wrong number of arguments. got=1, want=0"},
+	})
+}
+
+func TestSeed(t *testing.T) {
+	input := `[random(), randInt(0, 100), shuffle(range(10)), choice(range(10))]`
+
+	seeded := NewRuntime()
+	seeded.Rand = rand.New(rand.NewSource(7))
+	expected := object.Repr(seeded.Eval(context.Background(), testParseProgram(input), object.NewEnvironment()))
+
+	for i := 0; i < 2; i++ {
+		evaluated := testEval("seed(7); " + input)
+		if actual := object.Repr(evaluated); actual != expected {
+			t.Errorf("wrong result after seed(7). expected=%s, got=%s", expected, actual)
+		}
+	}
+}
+
+// fakeClock starts at a fixed time, which only moves when it sleeps.
+type fakeClock struct {
+	now time.Time
+}
+
+func (clock *fakeClock) Now() time.Time {
+	return clock.now
+}
+
+func (clock *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	clock.now = clock.now.Add(d)
+	return ctx.Err()
+}
+
+func TestTimeBuiltins(t *testing.T) {
+	tests := []builtinTest{
+		{`now()`, `1700000000000`},
+		{`let start = now(); sleep(1500); now() - start`, `1500`},
+		{`sleep(-1)`, "ERROR: negative duration -1 in call to `sleep`"},
+		{`sleep(9223372036854775807)`, "ERROR: duration 9223372036854775807 in call to `sleep` too long"},
+		{`formatTime(now())`, `"2023-11-14T22:13:20Z"`},
+		{`formatTime(0, "DateTime")`, `"1970-01-01 00:00:00"`},
+		{`formatTime(1500, "15:04:05.000")`, `"00:00:01.500"`},
+		{`parseTime("2023-11-14T22:13:20Z")`, `1700000000000`},
+		{`parseTime("2024-02-29", "DateOnly")`, `1709164800000`},
+		{`parseTime("01/02/2024 +0100", "01/02/2006 -0700")`, `1704150000000`},
+		{`parseTime(formatTime(now(), "RFC3339Nano"), "RFC3339Nano") == now()`, `true`},
+		{`parseTime("yesterday")`, `ERROR: invalid time: parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`},
+		{`formatTime("now")`, "ERROR: argument to `formatTime` must be INTEGER, got STRING"},
+	}
+
+	for _, test := range tests {
+		runtime := NewRuntime()
+		runtime.Clock = &fakeClock{now: time.UnixMilli(1700000000000)}
+
+		evaluated := runtime.Eval(context.Background(), testParseProgram(test.input), object.NewEnvironment())
+		if actual := object.Repr(evaluated); actual != test.expected {
+			t.Errorf("wrong result for %s.\nexpected=%s\ngot=     %s", test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestRegexBuiltins(t *testing.T) {
+	testBuiltins(t, []builtinTest{
+		{`regex("a+b")`, `/a+b/`},
+		{`type(regex("a"))`, `"REGEX"`},
+		{`regex("a(")`, "ERROR: invalid regular expression: error parsing regexp: missing closing ): `a(`"},
+		{`match("(\w+)@(\w+)\.com", "mail bob@example.com now")`, `["bob@example.com", "bob", "example"]`},
+		{`match(regex("(a)|(b)"), "b")`, `["b", null, "b"]`},
+		{`match("x", "abc")`, `null`},
+		{`matchAll("\d+", "1 22 333")`, `[["1"], ["22"], ["333"]]`},
+		{`matchAll("(\w)=(\d)", "a=1,b=2")`, `[["a=1", "a", "1"], ["b=2", "b", "2"]]`},
+		{`matchAll("z", "abc")`, `[]`},
+		{`replaceRegex("(\w+) (\w+)", "hello world", "$2 $1")`, `"world hello"`},
+		{`replaceRegex(regex("\s+"), "a  b   c", " ")`, `"a b c"`},
+		{`splitRegex("\s*,\s*", "a , b,c")`, `["a", "b", "c"]`},
+		{`let digits = regex("\d"); [len(splitRegex(digits, "a1b2c")), match(digits, "x9")]`, `[3, ["9"]]`},
+		{`match(1, "a")`, "ERROR: argument 1 to `match` must be REGEX or STRING, got INTEGER"},
+		{`match("a", 1)`, "ERROR: argument 2 to `match` must be STRING, got INTEGER"},
+		{`replaceRegex("a", "b", 1)`, "ERROR: argument 3 to `replaceRegex` must be STRING, got INTEGER"},
+		{`splitRegex("[", "a")`, "ERROR: invalid regular expression: error parsing regexp: missing closing ]: `[`"},
+		{`matchAll("a")`, "ERROR: wrong number of arguments. got=1, want=2"},
+	})
+}
+
+func TestHashBuiltins(t *testing.T) {
+	testBuiltins(t, []builtinTest{
+		{`len({"a": 1, "b": 2})`, `2`},
+		{`len({})`, `0`},
+		{`keys({"b": 1, "a": 2, 3: 3, true: 4})`, `[true, 3, "a", "b"]`},
+		{`values({"b": 1, "a": 2})`, `[2, 1]`},
+		{`entries({"b": 1, "a": 2})`, `[["a", 2], ["b", 1]]`},
+		{`keys([])`, "ERROR: argument to `keys` must be HASH, got ARRAY"},
+		{`has({"a": 1}, "a")`, `true`},
+		{`has({"a": 1}, "b")`, `false`},
+		{`has({"a": 1}, [])`, "ERROR: unusable as hash key: ARRAY"},
+		{`delete({"a": 1, "b": 2}, "a")`, `{"b": 2}`},
+		{`delete({"a": 1}, "z")`, `{"a": 1}`},
+		{`let h = {"a": 1}; delete(h, "a"); h`, `{"a": 1}`},
+		{`set({"a": 1}, "b", 2)`, `{"a": 1, "b": 2}`},
+		{`set({"a": 1}, "a", 2)`, `{"a": 2}`},
+		{`let h = {"a": 1}; set(h, "a", 2); h["a"]`, `1`},
+		{`set({}, fn() {}, 1)`, "ERROR: unusable as hash key: FUNCTION"},
+		{`merge({"a": 1, "b": 1}, {"b": 2}, {"c": 3})`, `{"a": 1, "b": 2, "c": 3}`},
+		{`merge({}, 1)`, "ERROR: argument 2 to `merge` must be HASH, got INTEGER"},
+	})
+}
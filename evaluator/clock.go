@@ -0,0 +1,120 @@
+package evaluator
+
+import (
+	"context"
+	"math"
+	"monkey/object"
+	"time"
+)
+
+// SystemClock is the clock of the operating system.
+var SystemClock object.Clock = systemClock{}
+
+type systemClock struct{}
+
+func (clock systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (clock systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// timeLayouts names the layouts of the time package that formatTime and
+// parseTime accept besides layouts written out.
+var timeLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+	"Kitchen":     time.Kitchen,
+}
+
+var timeBuiltins = map[string]*object.Builtin{
+	"now": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("now", args); err != nil {
+				return err
+			}
+			return &object.Integer{Value: ctx.Clock.Now().UnixMilli()}
+		},
+	},
+
+	"sleep": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("sleep", args, object.INTEGER_OBJ); err != nil {
+				return err
+			}
+
+			ms := args[0].(*object.Integer).Value
+			if ms < 0 {
+				return newError("negative duration %d in call to `sleep`", ms)
+			}
+			if ms > math.MaxInt64/int64(time.Millisecond) {
+				return newError("duration %d in call to `sleep` too long", ms)
+			}
+			if err := ctx.Clock.Sleep(ctx.Context, time.Duration(ms)*time.Millisecond); err != nil {
+				return wrapError(err)
+			}
+			return NULL
+		},
+	},
+
+	"formatTime": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) == 1 {
+				if err := checkArguments("formatTime", args, object.INTEGER_OBJ); err != nil {
+					return err
+				}
+			} else if err := checkArguments("formatTime", args, object.INTEGER_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+
+			layout := time.RFC3339
+			if len(args) == 2 {
+				layout = timeLayout(stringValue(args[1]))
+			}
+			formatted := time.UnixMilli(args[0].(*object.Integer).Value).UTC().Format(layout)
+			return &object.String{Value: formatted}
+		},
+	},
+
+	"parseTime": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) == 1 {
+				if err := checkArguments("parseTime", args, object.STRING_OBJ); err != nil {
+					return err
+				}
+			} else if err := checkArguments("parseTime", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+
+			layout := time.RFC3339
+			if len(args) == 2 {
+				layout = timeLayout(stringValue(args[1]))
+			}
+			parsed, err := time.Parse(layout, stringValue(args[0]))
+			if err != nil {
+				return newError("invalid time: %s", err)
+			}
+			return &object.Integer{Value: parsed.UnixMilli()}
+		},
+	},
+}
+
+func timeLayout(name string) string {
+	if layout, ok := timeLayouts[name]; ok {
+		return layout
+	}
+	return name
+}
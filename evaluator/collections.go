@@ -0,0 +1,367 @@
+package evaluator
+
+import (
+	"monkey/object"
+	"sort"
+)
+
+// maxArrayLength is the number of elements of the longest array range
+// builds. Every element is an integer object of its own, which makes the
+// array several gigabytes large at this length.
+const maxArrayLength = 1 << 27
+
+var collectionBuiltins = map[string]*object.Builtin{
+	"map": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("map", args, object.ARRAY_OBJ, object.FUNCTION_OBJ); err != nil {
+				return err
+			}
+
+			elements := args[0].(*object.Array).Elements
+			mapped := make([]object.Object, len(elements))
+			for i, element := range elements {
+				result := ctx.Apply(args[1], []object.Object{element})
+				if isError(result) {
+					return result
+				}
+				mapped[i] = result
+			}
+			return &object.Array{Elements: mapped}
+		},
+	},
+
+	"filter": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("filter", args, object.ARRAY_OBJ, object.FUNCTION_OBJ); err != nil {
+				return err
+			}
+
+			filtered := []object.Object{}
+			for _, element := range args[0].(*object.Array).Elements {
+				result := ctx.Apply(args[1], []object.Object{element})
+				if isError(result) {
+					return result
+				}
+				if isTruthy(result) {
+					filtered = append(filtered, element)
+				}
+			}
+			return &object.Array{Elements: filtered}
+		},
+	},
+
+	"reduce": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=3", len(args))
+			}
+			if err := checkArguments("reduce", []object.Object{args[0], args[2]}, object.ARRAY_OBJ, object.FUNCTION_OBJ); err != nil {
+				return err
+			}
+
+			result := args[1]
+			for _, element := range args[0].(*object.Array).Elements {
+				result = ctx.Apply(args[2], []object.Object{result, element})
+				if isError(result) {
+					return result
+				}
+			}
+			return result
+		},
+	},
+
+	"each": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("each", args, object.ARRAY_OBJ, object.FUNCTION_OBJ); err != nil {
+				return err
+			}
+
+			for _, element := range args[0].(*object.Array).Elements {
+				if result := ctx.Apply(args[1], []object.Object{element}); isError(result) {
+					return result
+				}
+			}
+			return NULL
+		},
+	},
+
+	"find": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("find", args, object.ARRAY_OBJ, object.FUNCTION_OBJ); err != nil {
+				return err
+			}
+
+			for _, element := range args[0].(*object.Array).Elements {
+				result := ctx.Apply(args[1], []object.Object{element})
+				if isError(result) {
+					return result
+				}
+				if isTruthy(result) {
+					return element
+				}
+			}
+			return NULL
+		},
+	},
+
+	"any": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("any", args, object.ARRAY_OBJ, object.FUNCTION_OBJ); err != nil {
+				return err
+			}
+
+			for _, element := range args[0].(*object.Array).Elements {
+				result := ctx.Apply(args[1], []object.Object{element})
+				if isError(result) {
+					return result
+				}
+				if isTruthy(result) {
+					return TRUE
+				}
+			}
+			return FALSE
+		},
+	},
+
+	"all": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("all", args, object.ARRAY_OBJ, object.FUNCTION_OBJ); err != nil {
+				return err
+			}
+
+			for _, element := range args[0].(*object.Array).Elements {
+				result := ctx.Apply(args[1], []object.Object{element})
+				if isError(result) {
+					return result
+				}
+				if !isTruthy(result) {
+					return FALSE
+				}
+			}
+			return TRUE
+		},
+	},
+
+	"sort": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) == 1 {
+				if err := checkArguments("sort", args, object.ARRAY_OBJ); err != nil {
+					return err
+				}
+			} else if err := checkArguments("sort", args, object.ARRAY_OBJ, object.FUNCTION_OBJ); err != nil {
+				return err
+			}
+
+			elements := args[0].(*object.Array).Elements
+			sorted := make([]object.Object, len(elements))
+			copy(sorted, elements)
+
+			var err object.Object
+			less := func(i, j int) bool {
+				if err != nil {
+					return false
+				}
+
+				var result object.Object
+				if len(args) == 2 {
+					result = ctx.Apply(args[1], []object.Object{sorted[i], sorted[j]})
+				} else {
+					result = compareObjects(sorted[i], sorted[j])
+				}
+				if isError(result) {
+					err = result
+					return false
+				}
+				return isTruthy(result)
+			}
+
+			sort.SliceStable(sorted, less)
+			if err != nil {
+				return err
+			}
+			return &object.Array{Elements: sorted}
+		},
+	},
+
+	"reverse": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) == 1 && args[0].Type() == object.STRING_OBJ {
+				runes := []rune(stringValue(args[0]))
+				for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+					runes[i], runes[j] = runes[j], runes[i]
+				}
+				return &object.String{Value: string(runes)}
+			}
+			if err := checkArguments("reverse", args, object.ARRAY_OBJ); err != nil {
+				return err
+			}
+
+			elements := args[0].(*object.Array).Elements
+			reversed := make([]object.Object, len(elements))
+			for i, element := range elements {
+				reversed[len(elements)-1-i] = element
+			}
+			return &object.Array{Elements: reversed}
+		},
+	},
+
+	"zip": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) < 2 {
+				return newError("wrong number of arguments. got=%d, want at least 2", len(args))
+			}
+
+			length := -1
+			for i, arg := range args {
+				arr, ok := arg.(*object.Array)
+				if !ok {
+					return newError("argument %d to `zip` must be ARRAY, got %s", i+1, arg.Type())
+				}
+				if length < 0 || len(arr.Elements) < length {
+					length = len(arr.Elements)
+				}
+			}
+
+			zipped := make([]object.Object, length)
+			for i := range zipped {
+				tuple := make([]object.Object, len(args))
+				for j, arg := range args {
+					tuple[j] = arg.(*object.Array).Elements[i]
+				}
+				zipped[i] = &object.Array{Elements: tuple}
+			}
+			return &object.Array{Elements: zipped}
+		},
+	},
+
+	"range": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) < 1 || len(args) > 3 {
+				return newError("wrong number of arguments. got=%d, want=1, 2 or 3", len(args))
+			}
+
+			bounds := make([]int64, len(args))
+			for i, arg := range args {
+				integer, ok := arg.(*object.Integer)
+				if !ok {
+					return newError("argument %d to `range` must be INTEGER, got %s", i+1, arg.Type())
+				}
+				bounds[i] = integer.Value
+			}
+
+			start, end, step := int64(0), bounds[0], int64(1)
+			if len(bounds) > 1 {
+				start, end = bounds[0], bounds[1]
+			}
+			if len(bounds) > 2 {
+				step = bounds[2]
+			}
+			if step == 0 {
+				return newError("step of `range` must not be zero")
+			}
+
+			// Compute the count in uint64, as the distance between the
+			// bounds may not fit an int64.
+			count := uint64(0)
+			if step > 0 && end > start {
+				count = (uint64(end)-uint64(start)-1)/uint64(step) + 1
+			} else if step < 0 && end < start {
+				count = (uint64(start)-uint64(end)-1)/absUint64(step) + 1
+			}
+			if count > maxArrayLength {
+				return newError("result of `range` too long")
+			}
+			if err := ctx.CheckAlloc(24 + 16*int64(count)); err != nil {
+				return wrapError(err)
+			}
+
+			elements := make([]object.Object, count)
+			for i := range elements {
+				elements[i] = &object.Integer{Value: start + int64(i)*step}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+
+	"flatten": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("flatten", args, object.ARRAY_OBJ); err != nil {
+				return err
+			}
+
+			flattened := []object.Object{}
+			for _, element := range args[0].(*object.Array).Elements {
+				if inner, ok := element.(*object.Array); ok {
+					flattened = append(flattened, inner.Elements...)
+				} else {
+					flattened = append(flattened, element)
+				}
+			}
+			return &object.Array{Elements: flattened}
+		},
+	},
+
+	"uniq": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("uniq", args, object.ARRAY_OBJ); err != nil {
+				return err
+			}
+
+			seen := make(map[object.HashKey]bool)
+			unique := []object.Object{}
+			for _, element := range args[0].(*object.Array).Elements {
+				hashable, ok := element.(object.Hashable)
+				if !ok {
+					return newError("unusable as hash key: %s", element.Type())
+				}
+				if key := hashable.HashKey(); !seen[key] {
+					seen[key] = true
+					unique = append(unique, element)
+				}
+			}
+			return &object.Array{Elements: unique}
+		},
+	},
+
+	"slice": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) == 2 {
+				if err := checkArguments("slice", args, object.ARRAY_OBJ, object.INTEGER_OBJ); err != nil {
+					return err
+				}
+			} else if err := checkArguments("slice", args, object.ARRAY_OBJ, object.INTEGER_OBJ, object.INTEGER_OBJ); err != nil {
+				return err
+			}
+
+			elements := args[0].(*object.Array).Elements
+			start := args[1].(*object.Integer).Value
+			end := int64(len(elements))
+			if len(args) == 3 {
+				end = min(args[2].(*object.Integer).Value, end)
+			}
+			if start < 0 || start > int64(len(elements)) || end < start {
+				return newError("slice [%d:%d] out of range for array of length %d", start, end, len(elements))
+			}
+
+			sliced := make([]object.Object, end-start)
+			copy(sliced, elements[start:end])
+			return &object.Array{Elements: sliced}
+		},
+	},
+}
+
+// compareObjects reports whether left sorts before right, for the default
+// order of sort.
+func compareObjects(left, right object.Object) object.Object {
+	switch {
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return nativeBoolToBooleanObject(left.(*object.Integer).Value < right.(*object.Integer).Value)
+	case isNumber(left) && isNumber(right):
+		return nativeBoolToBooleanObject(floatValue(left) < floatValue(right))
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return nativeBoolToBooleanObject(stringValue(left) < stringValue(right))
+	default:
+		return newError("cannot compare %s with %s, pass a comparison function to `sort`",
+			left.Type(), right.Type())
+	}
+}
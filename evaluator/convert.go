@@ -0,0 +1,460 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"monkey/object"
+	"reflect"
+	"strings"
+)
+
+var (
+	errorType       = reflect.TypeOf((*error)(nil)).Elem()
+	contextType     = reflect.TypeOf((*context.Context)(nil)).Elem()
+	execContextType = reflect.TypeOf((*object.ExecContext)(nil))
+)
+
+// NewBuiltin wraps the Go function fn as a builtin named name. Arguments
+// are converted from Monkey values to the parameter types of fn with
+// FromObject and results back with ToObject. Leading parameters of type
+// context.Context and *object.ExecContext receive the context of the call
+// instead of arguments. fn may return nothing, a value, an error, or a
+// value and an error; a non-nil error and failed conversions become
+// Monkey errors.
+func NewBuiltin(name string, fn interface{}) (*object.Builtin, error) {
+	switch fn := fn.(type) {
+	case object.BuiltinFunction:
+		return &object.Builtin{Fn: fn}, nil
+	case func(ctx *object.ExecContext, args ...object.Object) object.Object:
+		return &object.Builtin{Fn: fn}, nil
+	case func(args ...object.Object) object.Object:
+		return &object.Builtin{Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			return fn(args...)
+		}}, nil
+	}
+
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func {
+		return nil, fmt.Errorf("builtin %s is not a function: %T", name, fn)
+	}
+
+	fnType := value.Type()
+	switch {
+	case fnType.NumOut() > 2:
+		return nil, fmt.Errorf("builtin %s returns more than two values", name)
+	case fnType.NumOut() == 2 && fnType.Out(1) != errorType:
+		return nil, fmt.Errorf("second result of builtin %s is not an error", name)
+	}
+
+	return &object.Builtin{Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+		in, err := convertArguments(name, fnType, ctx, args)
+		if err != nil {
+			return err
+		}
+
+		return convertResults(name, value.Call(in))
+	}}, nil
+}
+
+func convertArguments(name string, fnType reflect.Type, ctx *object.ExecContext, args []object.Object) ([]reflect.Value, *object.Error) {
+	in := []reflect.Value{}
+
+	injected := 0
+	for injected < fnType.NumIn() {
+		if paramType := fnType.In(injected); paramType == contextType {
+			in = append(in, reflect.ValueOf(&ctx.Context).Elem())
+		} else if paramType == execContextType {
+			in = append(in, reflect.ValueOf(ctx))
+		} else {
+			break
+		}
+		injected += 1
+	}
+
+	params := fnType.NumIn() - injected
+	if fnType.IsVariadic() {
+		if len(args) < params-1 {
+			return nil, newError("wrong number of arguments to `%s`. got=%d, want at least %d", name, len(args), params-1)
+		}
+	} else if len(args) != params {
+		return nil, newError("wrong number of arguments to `%s`. got=%d, want=%d", name, len(args), params)
+	}
+
+	for i, arg := range args {
+		var paramType reflect.Type
+		if fnType.IsVariadic() && i >= params-1 {
+			paramType = fnType.In(fnType.NumIn() - 1).Elem()
+		} else {
+			paramType = fnType.In(injected + i)
+		}
+
+		value, err := fromObject(arg, paramType)
+		if err != nil {
+			return nil, newError("argument %d to `%s`: %s", i+1, name, err)
+		}
+		in = append(in, value)
+	}
+
+	return in, nil
+}
+
+func convertResults(name string, out []reflect.Value) object.Object {
+	if len(out) > 0 && out[len(out)-1].Type() == errorType {
+		if err, _ := out[len(out)-1].Interface().(error); err != nil {
+			return newError("%s", err)
+		}
+		out = out[:len(out)-1]
+	}
+
+	if len(out) == 0 {
+		return NULL
+	}
+
+	result, err := ToObject(out[0].Interface())
+	if err != nil {
+		return newError("result of `%s`: %s", name, err)
+	}
+	return result
+}
+
+// ToObject converts a Go value to a Monkey value. Integers, strings and
+// booleans become their Monkey counterparts, slices and arrays become
+// arrays, maps and structs become hashes, functions become builtins and
+// nil becomes NULL. Values that already are objects are returned as is.
+func ToObject(value interface{}) (object.Object, error) {
+	if value == nil {
+		return NULL, nil
+	}
+	if obj, ok := value.(object.Object); ok {
+		return obj, nil
+	}
+	return toObject(reflect.ValueOf(value), make(map[visit]bool))
+}
+
+// visit identifies a pointer, map or slice being converted. Slices are
+// told apart by length too, as a slice and its prefix share a pointer.
+type visit struct {
+	pointer uintptr
+	typ     reflect.Type
+	length  int
+}
+
+// toObject converts value. Pointers, maps and slices being converted are
+// kept in path, to report cycles instead of recursing forever.
+func toObject(value reflect.Value, path map[visit]bool) (object.Object, error) {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if !value.IsNil() && (value.Kind() != reflect.Slice || value.Len() > 0) {
+			key := visit{value.Pointer(), value.Type(), 0}
+			if value.Kind() == reflect.Slice {
+				key.length = value.Len()
+			}
+			if path[key] {
+				return nil, fmt.Errorf("cannot convert cyclic %s", value.Type())
+			}
+			path[key] = true
+			defer delete(path, key)
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		return nativeBoolToBooleanObject(value.Bool()), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &object.Integer{Value: value.Int()}, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if value.Uint() > 1<<63-1 {
+			return nil, fmt.Errorf("%d overflows INTEGER", value.Uint())
+		}
+		return &object.Integer{Value: int64(value.Uint())}, nil
+
+	case reflect.Float32, reflect.Float64:
+		return &object.Float{Value: value.Float()}, nil
+
+	case reflect.String:
+		return &object.String{Value: value.String()}, nil
+
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return NULL, nil
+		}
+
+		elements := make([]object.Object, value.Len())
+		for i := range elements {
+			element, err := toObject(value.Index(i), path)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = element
+		}
+		return &object.Array{Elements: elements}, nil
+
+	case reflect.Map:
+		if value.IsNil() {
+			return NULL, nil
+		}
+
+		hash := &object.Hash{Pairs: make(map[object.HashKey]object.HashPair)}
+		iter := value.MapRange()
+		for iter.Next() {
+			if err := setPair(hash, iter.Key(), iter.Value(), path); err != nil {
+				return nil, err
+			}
+		}
+		return hash, nil
+
+	case reflect.Struct:
+		hash := &object.Hash{Pairs: make(map[object.HashKey]object.HashPair)}
+		for i := 0; i < value.NumField(); i++ {
+			name, ok := fieldName(value.Type().Field(i))
+			if !ok {
+				continue
+			}
+			if err := setPair(hash, reflect.ValueOf(name), value.Field(i), path); err != nil {
+				return nil, err
+			}
+		}
+		return hash, nil
+
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return NULL, nil
+		}
+		if obj, ok := value.Interface().(object.Object); ok {
+			return obj, nil
+		}
+		return toObject(value.Elem(), path)
+
+	case reflect.Func:
+		if value.IsNil() {
+			return NULL, nil
+		}
+		return NewBuiltin(value.Type().String(), value.Interface())
+
+	default:
+		return nil, fmt.Errorf("cannot convert %s", value.Type())
+	}
+}
+
+func setPair(hash *object.Hash, key, value reflect.Value, path map[visit]bool) error {
+	keyObject, err := toObject(key, path)
+	if err != nil {
+		return err
+	}
+
+	hashKey, ok := keyObject.(object.Hashable)
+	if !ok {
+		return fmt.Errorf("unusable as hash key: %s", keyObject.Type())
+	}
+
+	valueObject, err := toObject(value, path)
+	if err != nil {
+		return err
+	}
+
+	hash.Pairs[hashKey.HashKey()] = object.HashPair{Key: keyObject, Value: valueObject}
+	return nil
+}
+
+// fieldName returns the hash key for a struct field: the name from its
+// json tag if it has one, its Go name otherwise. Unexported fields and
+// fields tagged "-" have no key.
+func fieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return field.Name, true
+	default:
+		return name, true
+	}
+}
+
+// FromObject stores the Monkey value obj in the Go value target points
+// to, reversing the conversions of ToObject. Into an empty interface,
+// integers convert to int64, arrays to []interface{} and hashes with
+// string keys to map[string]interface{}.
+func FromObject(obj object.Object, target interface{}) error {
+	pointer := reflect.ValueOf(target)
+	if pointer.Kind() != reflect.Pointer || pointer.IsNil() {
+		return fmt.Errorf("target is not a non-nil pointer: %T", target)
+	}
+
+	value, err := fromObject(obj, pointer.Type().Elem())
+	if err != nil {
+		return err
+	}
+
+	pointer.Elem().Set(value)
+	return nil
+}
+
+func fromObject(obj object.Object, typ reflect.Type) (reflect.Value, error) {
+	if typ.Kind() == reflect.Interface && typ.NumMethod() == 0 {
+		return fromObjectToInterface(obj, typ)
+	}
+	if reflect.TypeOf(obj).AssignableTo(typ) {
+		return reflect.ValueOf(obj), nil
+	}
+
+	mismatch := fmt.Errorf("cannot use %s as %s", obj.Type(), typ)
+
+	if obj == NULL {
+		switch typ.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map:
+			return reflect.Zero(typ), nil
+		default:
+			return reflect.Value{}, mismatch
+		}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		if boolean, ok := obj.(*object.Boolean); ok {
+			return reflect.ValueOf(boolean.Value).Convert(typ), nil
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if integer, ok := obj.(*object.Integer); ok {
+			value := reflect.New(typ).Elem()
+			if value.OverflowInt(integer.Value) {
+				return reflect.Value{}, fmt.Errorf("%d overflows %s", integer.Value, typ)
+			}
+			value.SetInt(integer.Value)
+			return value, nil
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if integer, ok := obj.(*object.Integer); ok {
+			value := reflect.New(typ).Elem()
+			if integer.Value < 0 || value.OverflowUint(uint64(integer.Value)) {
+				return reflect.Value{}, fmt.Errorf("%d overflows %s", integer.Value, typ)
+			}
+			value.SetUint(uint64(integer.Value))
+			return value, nil
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if isNumber(obj) {
+			return reflect.ValueOf(floatValue(obj)).Convert(typ), nil
+		}
+
+	case reflect.String:
+		if str, ok := obj.(*object.String); ok {
+			return reflect.ValueOf(str.Value).Convert(typ), nil
+		}
+
+	case reflect.Slice:
+		if arr, ok := obj.(*object.Array); ok {
+			value := reflect.MakeSlice(typ, len(arr.Elements), len(arr.Elements))
+			return value, fromElements(arr, value)
+		}
+
+	case reflect.Array:
+		if arr, ok := obj.(*object.Array); ok {
+			if len(arr.Elements) != typ.Len() {
+				return reflect.Value{}, fmt.Errorf("cannot use ARRAY of length %d as %s", len(arr.Elements), typ)
+			}
+			value := reflect.New(typ).Elem()
+			return value, fromElements(arr, value)
+		}
+
+	case reflect.Map:
+		if hash, ok := obj.(*object.Hash); ok {
+			value := reflect.MakeMapWithSize(typ, len(hash.Pairs))
+			for _, pair := range hash.Pairs {
+				key, err := fromObject(pair.Key, typ.Key())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				element, err := fromObject(pair.Value, typ.Elem())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				value.SetMapIndex(key, element)
+			}
+			return value, nil
+		}
+
+	case reflect.Struct:
+		if hash, ok := obj.(*object.Hash); ok {
+			value := reflect.New(typ).Elem()
+			for i := 0; i < typ.NumField(); i++ {
+				name, ok := fieldName(typ.Field(i))
+				if !ok {
+					continue
+				}
+				pair, ok := hash.Pairs[(&object.String{Value: name}).HashKey()]
+				if !ok {
+					continue
+				}
+				field, err := fromObject(pair.Value, typ.Field(i).Type)
+				if err != nil {
+					return reflect.Value{}, fmt.Errorf("field %s: %s", name, err)
+				}
+				value.Field(i).Set(field)
+			}
+			return value, nil
+		}
+
+	case reflect.Pointer:
+		element, err := fromObject(obj, typ.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value := reflect.New(typ.Elem())
+		value.Elem().Set(element)
+		return value, nil
+	}
+
+	return reflect.Value{}, mismatch
+}
+
+func fromElements(arr *object.Array, value reflect.Value) error {
+	for i, element := range arr.Elements {
+		converted, err := fromObject(element, value.Type().Elem())
+		if err != nil {
+			return err
+		}
+		value.Index(i).Set(converted)
+	}
+	return nil
+}
+
+func fromObjectToInterface(obj object.Object, typ reflect.Type) (reflect.Value, error) {
+	var natural reflect.Type
+	switch obj.(type) {
+	case *object.Null:
+		return reflect.Zero(typ), nil
+	case *object.Boolean:
+		natural = reflect.TypeOf(false)
+	case *object.Integer:
+		natural = reflect.TypeOf(int64(0))
+	case *object.Float:
+		natural = reflect.TypeOf(float64(0))
+	case *object.String:
+		natural = reflect.TypeOf("")
+	case *object.Array:
+		natural = reflect.TypeOf([]interface{}{})
+	case *object.Hash:
+		natural = reflect.TypeOf(map[string]interface{}{})
+	default:
+		return reflect.ValueOf(obj), nil
+	}
+
+	value, err := fromObject(obj, natural)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	result := reflect.New(typ).Elem()
+	result.Set(value)
+	return result, nil
+}
@@ -0,0 +1,159 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"monkey/object"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type point struct {
+	X     int64
+	Y     int64  `json:"y"`
+	Label string `json:"label,omitempty"`
+	Skip  string `json:"-"`
+	note  string
+}
+
+type node struct {
+	Value int64
+	Next  *node
+}
+
+func TestNewBuiltin(t *testing.T) {
+	functions := map[string]interface{}{
+		"greet": func(name string, times int) string { return strings.Repeat("hi "+name+" ", times) },
+		"isLong": func(s string, limit int) (bool, error) {
+			if limit < 0 {
+				return false, errors.New("negative limit")
+			}
+			return len(s) > limit, nil
+		},
+		"sum": func(numbers ...int64) int64 {
+			var total int64
+			for _, n := range numbers {
+				total += n
+			}
+			return total
+		},
+		"norm":   func(p point) int64 { return p.X*p.X + p.Y*p.Y },
+		"origin": func() point { return point{Label: "origin", Skip: "x", note: "y"} },
+		"counts": func(words []string) map[string]int {
+			counts := map[string]int{}
+			for _, word := range words {
+				counts[word] += 1
+			}
+			return counts
+		},
+		"nothing": func() {},
+		"small":   func(n uint8) uint8 { return n },
+		"apply": func(ctx *object.ExecContext, fn object.Object, args ...object.Object) object.Object {
+			return ctx.Apply(fn, args)
+		},
+		"deadline": func(ctx context.Context) bool {
+			_, ok := ctx.Deadline()
+			return ok
+		},
+		"float": func() float64 { return 1.5 },
+		"loop": func() *node {
+			n := &node{Value: 1}
+			n.Next = n
+			return n
+		},
+		"cyclicMap": func() map[string]interface{} {
+			m := map[string]interface{}{}
+			m["self"] = m
+			return m
+		},
+		"cyclicSlice": func() []interface{} {
+			s := []interface{}{nil}
+			s[0] = s
+			return s
+		},
+		"shared": func() []*node {
+			n := &node{Value: 7}
+			return []*node{n, n}
+		},
+	}
+
+	env := object.NewEnvironment()
+	for name, fn := range functions {
+		builtin, err := NewBuiltin(name, fn)
+		if err != nil {
+			t.Fatalf("NewBuiltin(%q) returned error: %s", name, err)
+		}
+		env.Set(name, builtin)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`greet("bob", 2)`, "hi bob hi bob "},
+		{`isLong("monkey", 3)`, "true"},
+		{`isLong("monkey", -1)`, "ERROR: negative limit"},
+		{`sum()`, "0"},
+		{`sum(1, 2, 3)`, "6"},
+		{`norm({"X": 3, "y": 4})`, "25"},
+		{`origin()["label"]`, "origin"},
+		{`origin()["X"]`, "0"},
+		{`origin()["Skip"]`, "null"},
+		{`counts(["a", "b", "a"])["a"]`, "2"},
+		{`nothing()`, "null"},
+		{`deadline()`, "false"},
+		{`small(255)`, "255"},
+		{`apply(fn(x, y) { x * y }, 6, 7)`, "42"},
+		{`greet("bob")`, "ERROR: wrong number of arguments to `greet`. got=1, want=2"},
+		{`greet(1, 2)`, "ERROR: argument 1 to `greet`: cannot use INTEGER as string"},
+		{`small(256)`, "ERROR: argument 1 to `small`: 256 overflows uint8"},
+		{`sum(1, "2")`, "ERROR: argument 2 to `sum`: cannot use STRING as int64"},
+		{`norm({"X": "3"})`, "ERROR: argument 1 to `norm`: field X: cannot use STRING as int64"},
+		{`float()`, "1.5"},
+		{`loop()`, "ERROR: result of `loop`: cannot convert cyclic *evaluator.node"},
+		{`cyclicMap()`, "ERROR: result of `cyclicMap`: cannot convert cyclic map[string]interface {}"},
+		{`cyclicSlice()`, "ERROR: result of `cyclicSlice`: cannot convert cyclic []interface {}"},
+		{`shared()[1]["Value"]`, "7"},
+	}
+
+	for _, test := range tests {
+		program := testParseProgram(test.input)
+		evaluated := NewRuntime().Eval(context.Background(), program, env)
+		if evaluated.Inspect() != test.expected {
+			t.Errorf("wrong result for %s. expected=%q, got=%q", test.input, test.expected, evaluated.Inspect())
+		}
+	}
+
+	if _, err := NewBuiltin("bad", 5); err == nil {
+		t.Errorf("expected error for non-function builtin")
+	}
+	if _, err := NewBuiltin("bad", func() (int, int) { return 1, 2 }); err == nil {
+		t.Errorf("expected error for builtin with two non-error results")
+	}
+}
+
+func TestFromObject(t *testing.T) {
+	var generic interface{}
+	if err := FromObject(testEval(`{"a": [1, "b", true, if (false) { 1 }]}`), &generic); err != nil {
+		t.Fatalf("FromObject returned error: %s", err)
+	}
+
+	expected := map[string]interface{}{"a": []interface{}{int64(1), "b", true, nil}}
+	if !reflect.DeepEqual(generic, expected) {
+		t.Errorf("wrong conversion. expected=%#v, got=%#v", expected, generic)
+	}
+
+	var p *point
+	if err := FromObject(testEval(`{"X": 1, "y": 2, "other": 3}`), &p); err != nil {
+		t.Fatalf("FromObject returned error: %s", err)
+	}
+	if p == nil || p.X != 1 || p.Y != 2 {
+		t.Errorf("wrong conversion. got=%+v", p)
+	}
+
+	var numbers [2]int
+	if err := FromObject(testEval(`[1, 2, 3]`), &numbers); err == nil {
+		t.Errorf("expected error converting array of wrong length")
+	}
+}
@@ -1,6 +1,7 @@
 package evaluator
 
 import (
+	"context"
 	"fmt"
 	"monkey/ast"
 	"monkey/object"
@@ -59,10 +60,35 @@ func addMacro(statement ast.Statement, env *object.Environment) {
 	env.Set(letStatement.Name.Value, macro)
 }
 
-func ExpandMacros(program *ast.Program, env *object.Environment) ast.Node {
-	return ast.Modify(program, func(node ast.Node) ast.Node {
+// ExpandMacros expands the macro calls in program with a new Runtime that
+// cannot be canceled.
+func ExpandMacros(program *ast.Program, env *object.Environment) (ast.Node, *object.Error) {
+	return NewRuntime().ExpandMacros(context.Background(), program, env)
+}
+
+// ExpandMacros replaces the macro calls in program with the code returned
+// by the macros. It returns an error if a macro fails or returns anything
+// but a quote, and like Eval stops when ctx is done.
+func (runtime *Runtime) ExpandMacros(ctx context.Context, program *ast.Program, env *object.Environment) (ast.Node, *object.Error) {
+	runtime.ctx = ctx
+	runtime.steps = 0
+	runtime.bytes = 0
+	runtime.exec = nil
+
+	if err := ctx.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+	return runtime.expandMacros(program, env)
+}
+
+// expandMacros expands the macro calls in program as part of the current
+// evaluation of runtime.
+func (runtime *Runtime) expandMacros(program *ast.Program, env *object.Environment) (ast.Node, *object.Error) {
+	var err *object.Error
+
+	expanded := ast.Modify(program, func(node ast.Node) ast.Node {
 		callExpression, ok := node.(*ast.CallExpression)
-		if !ok {
+		if !ok || err != nil {
 			return node
 		}
 
@@ -74,15 +100,35 @@ func ExpandMacros(program *ast.Program, env *object.Environment) ast.Node {
 		args := quoteArgs(callExpression)
 		evalEnv := extendMacroEnv(macro, args)
 
-		evaluated := Eval(macro.Body, evalEnv)
+		evaluated := runtime.eval(macro.Body, evalEnv)
 
 		quote, ok := evaluated.(*object.Quote)
 		if !ok {
-			panic("we only support returning AST-nodes from macros")
+			err = macroError(callExpression, evaluated)
+			return node
 		}
 
 		return quote.Node
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return expanded, nil
+}
+
+// macroError returns the error of the macro call expr, which evaluated to
+// result instead of a quote.
+func macroError(expr *ast.CallExpression, result object.Object) *object.Error {
+	if err, ok := result.(*object.Error); ok {
+		return err
+	}
+
+	got := "nothing"
+	if result != nil {
+		got = string(result.Type())
+	}
+	return newError("macro `%s` must return a QUOTE, got %s", expr.Function.String(), got)
 }
 
 func isMacroCall(expr *ast.CallExpression, env *object.Environment) (*object.Macro, bool) {
@@ -124,68 +170,93 @@ func extendMacroEnv(macro *object.Macro, args []*object.Quote) *object.Environme
 	return extended
 }
 
-func Eval(node ast.Node, env *object.Environment) object.Object {
+func (runtime *Runtime) eval(node ast.Node, env *object.Environment) object.Object {
+	if err := runtime.step(); err != nil {
+		return err
+	}
+
 	switch node := node.(type) {
 	case *ast.Program:
-		return evalProgram(node, env)
+		return runtime.evalProgram(node, env)
 	case *ast.BlockStatement:
-		return evalBlockStatement(node, env)
+		return runtime.evalBlockStatement(node, env)
 	case *ast.ExpressionStatement:
-		return Eval(node.Expression, env)
+		return runtime.eval(node.Expression, env)
 	case *ast.ReturnStatement:
-		val := Eval(node.ReturnValue, env)
+		val := runtime.eval(node.ReturnValue, env)
 		if isError(val) {
 			return val
 		}
 		return &object.ReturnValue{Value: val}
 	case *ast.LetStatement:
-		val := Eval(node.Value, env)
+		val := runtime.eval(node.Value, env)
 		if isError(val) {
 			return val
 		}
 		env.Set(node.Name.Value, val)
+	case *ast.ImportStatement:
+		module := runtime.importModule(node.Path.Value)
+		if isError(module) {
+			return module
+		}
+		env.Set(node.Name.Value, module)
+	case *ast.ImportExpression:
+		path := runtime.eval(node.Path, env)
+		if isError(path) {
+			return path
+		}
+		if path.Type() != object.STRING_OBJ {
+			return newError("argument to `import` must be STRING, got %s", path.Type())
+		}
+		return runtime.importModule(stringValue(path))
 	case *ast.PrefixExpression:
-		right := Eval(node.Right, env)
+		right := runtime.eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
 		return evalPrefixExpression(node.Operator, right)
 	case *ast.InfixExpression:
-		left := Eval(node.Left, env)
+		left := runtime.eval(node.Left, env)
 		if isError(left) {
 			return left
 		}
-		right := Eval(node.Right, env)
+		right := runtime.eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
-		return evalInfixExpression(node.Operator, left, right)
+		return runtime.alloc(evalInfixExpression(node.Operator, left, right))
 	case *ast.IfExpression:
-		return evalIfExpression(node, env)
+		return runtime.evalIfExpression(node, env)
 	case *ast.CallExpression:
 		if node.Function.TokenLiteral() == "quote" {
-			return quote(node.Arguments[0], env)
+			return runtime.quote(node.Arguments[0], env)
 		}
 
-		function := Eval(node.Function, env)
+		function := runtime.eval(node.Function, env)
 		if isError(function) {
 			return function
 		}
-		args := evalExpressions(node.Arguments, env)
+		args := runtime.evalExpressions(node.Arguments, env)
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return applyFunction(function, args)
+		return runtime.applyFunction(function, args)
 	case *ast.IndexExpression:
-		left := Eval(node.Left, env)
+		left := runtime.eval(node.Left, env)
 		if isError(left) {
 			return left
 		}
-		index := Eval(node.Index, env)
+		index := runtime.eval(node.Index, env)
 		if isError(index) {
 			return index
 		}
 		return evalIndexExpression(left, index)
+	case *ast.MemberExpression:
+		left := runtime.eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		return evalMemberExpression(left, node.Member.Value)
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 	case *ast.FunctionLiteral:
@@ -193,15 +264,17 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		body := node.Body
 		return &object.Function{Parameters: params, Env: env, Body: body}
 	case *ast.ArrayLiteral:
-		elements := evalExpressions(node.Elements, env)
+		elements := runtime.evalExpressions(node.Elements, env)
 		if len(elements) == 1 && isError(elements[0]) {
 			return elements[0]
 		}
-		return &object.Array{Elements: elements}
+		return runtime.alloc(&object.Array{Elements: elements})
 	case *ast.HashLiteral:
-		return evalHashLiteral(node, env)
+		return runtime.alloc(runtime.evalHashLiteral(node, env))
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 	case *ast.StringLiteral:
@@ -211,11 +284,11 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	return nil
 }
 
-func evalProgram(program *ast.Program, env *object.Environment) object.Object {
+func (runtime *Runtime) evalProgram(program *ast.Program, env *object.Environment) object.Object {
 	var result object.Object
 
 	for _, statement := range program.Statements {
-		result = Eval(statement, env)
+		result = runtime.eval(statement, env)
 
 		switch result := result.(type) {
 		case *object.ReturnValue:
@@ -228,11 +301,11 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 	return result
 }
 
-func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+func (runtime *Runtime) evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
 	var result object.Object
 
 	for _, statement := range block.Statements {
-		result = Eval(statement, env)
+		result = runtime.eval(statement, env)
 
 		if result != nil {
 			resultType := result.Type()
@@ -245,11 +318,11 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 	return result
 }
 
-func evalStatements(statements []ast.Statement, env *object.Environment) object.Object {
+func (runtime *Runtime) evalStatements(statements []ast.Statement, env *object.Environment) object.Object {
 	var result object.Object
 
 	for _, statement := range statements {
-		result = Eval(statement, env)
+		result = runtime.eval(statement, env)
 
 		if returnValue, ok := result.(*object.ReturnValue); ok {
 			return returnValue.Value
@@ -274,6 +347,8 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(operator, left, right)
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
 	case operator == "==":
@@ -301,6 +376,9 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	case "*":
 		return &object.Integer{Value: leftVal * rightVal}
 	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
 		return &object.Integer{Value: leftVal / rightVal}
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
@@ -316,6 +394,50 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	}
 }
 
+// evalFloatInfixExpression evaluates an operator on two numbers, at least
+// one of them a float.
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := floatValue(left)
+	rightVal := floatValue(right)
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return &object.Float{Value: leftVal / rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
+}
+
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+// floatValue returns the value of the integer or float obj as a float.
+func floatValue(obj object.Object) float64 {
+	if integer, ok := obj.(*object.Integer); ok {
+		return float64(integer.Value)
+	}
+	return obj.(*object.Float).Value
+}
+
 func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
 	if operator != "+" {
 		return newError("unknown operator: %s %s %s",
@@ -341,24 +463,26 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: -right.Value}
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
-
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
 }
 
-func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
-	condition := Eval(ie.Condition, env)
+func (runtime *Runtime) evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
+	condition := runtime.eval(ie.Condition, env)
 	if isError(condition) {
 		return condition
 	}
 
 	if isTruthy(condition) {
-		return Eval(ie.Consequence, env)
+		return runtime.eval(ie.Consequence, env)
 	} else if ie.Alternative != nil {
-		return Eval(ie.Alternative, env)
+		return runtime.eval(ie.Alternative, env)
 	} else {
 		return NULL
 	}
@@ -377,11 +501,11 @@ func isTruthy(obj object.Object) bool {
 	}
 }
 
-func evalExpressions(expressions []ast.Expression, env *object.Environment) []object.Object {
+func (runtime *Runtime) evalExpressions(expressions []ast.Expression, env *object.Environment) []object.Object {
 	var result []object.Object
 
 	for _, expression := range expressions {
-		evaluated := Eval(expression, env)
+		evaluated := runtime.eval(expression, env)
 		if isError(evaluated) {
 			return []object.Object{evaluated}
 		}
@@ -391,15 +515,32 @@ func evalExpressions(expressions []ast.Expression, env *object.Environment) []ob
 	return result
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+func (runtime *Runtime) applyFunction(fn object.Object, args []object.Object) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
-		extendedEnv := extendFunctionEnv(fn, args)
-		evaluated := Eval(fn.Body, extendedEnv)
-		return unwrapReturnValue(evaluated)
+		if err := runtime.enter(); err != nil {
+			return err
+		}
+		defer runtime.leave()
+
+		for {
+			if len(args) < len(fn.Parameters) {
+				return newError("wrong number of arguments. got=%d, want=%d",
+					len(args), len(fn.Parameters))
+			}
+
+			extendedEnv := extendFunctionEnv(fn, args)
+			evaluated := runtime.evalTail(fn.Body, extendedEnv)
+
+			call, ok := evaluated.(*tailCall)
+			if !ok {
+				return unwrapReturnValue(evaluated)
+			}
+			fn, args = call.fn, call.args
+		}
 
 	case *object.Builtin:
-		return fn.Fn(args...)
+		return runtime.alloc(fn.Fn(runtime.execContext(), args...))
 
 	default:
 		return newError("not a function: %s", fn.Type())
@@ -434,6 +575,21 @@ func evalIndexExpression(left, index object.Object) object.Object {
 	}
 }
 
+func evalMemberExpression(left object.Object, name string) object.Object {
+	switch left := left.(type) {
+	case *object.Module:
+		member, ok := left.Members[name]
+		if !ok {
+			return newError("module %s has no member %s", left.Name, name)
+		}
+		return member
+	case *object.Hash:
+		return evalHashIndexExpression(left, &object.String{Value: name})
+	default:
+		return newError("member access not supported: %s", left.Type())
+	}
+}
+
 func evalArrayIndexExpression(arr, index object.Object) object.Object {
 	arrayObject := arr.(*object.Array)
 	arrayIndex := index.(*object.Integer).Value
@@ -462,11 +618,11 @@ func evalHashIndexExpression(hash, index object.Object) object.Object {
 	return pair.Value
 }
 
-func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+func (runtime *Runtime) evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
 	pairs := make(map[object.HashKey]object.HashPair)
 
 	for keyNode, valueNode := range node.Pairs {
-		key := Eval(keyNode, env)
+		key := runtime.eval(keyNode, env)
 		if isError(key) {
 			return key
 		}
@@ -476,7 +632,7 @@ func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Obje
 			return newError("unusable as hash key: %s", key.Type())
 		}
 
-		value := Eval(valueNode, env)
+		value := runtime.eval(valueNode, env)
 		if isError(value) {
 			return value
 		}
@@ -497,7 +653,11 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 		return builtin
 	}
 
-	return newError("identifier not found: " + node.Value)
+	if module, ok := builtinModules[node.Value]; ok {
+		return module
+	}
+
+	return newError("identifier not found: %s", node.Value)
 }
 
 func nativeBoolToBooleanObject(input bool) *object.Boolean {
@@ -518,12 +678,12 @@ func isError(obj object.Object) bool {
 	return false
 }
 
-func quote(node ast.Node, env *object.Environment) object.Object {
-	node = evalUnquoteCalls(node, env)
+func (runtime *Runtime) quote(node ast.Node, env *object.Environment) object.Object {
+	node = runtime.evalUnquoteCalls(node, env)
 	return &object.Quote{Node: node}
 }
 
-func evalUnquoteCalls(quoted ast.Node, env *object.Environment) ast.Node {
+func (runtime *Runtime) evalUnquoteCalls(quoted ast.Node, env *object.Environment) ast.Node {
 	return ast.Modify(quoted, func(node ast.Node) ast.Node {
 		if !isUnquoteCall(node) {
 			return node
@@ -538,7 +698,7 @@ func evalUnquoteCalls(quoted ast.Node, env *object.Environment) ast.Node {
 			return node
 		}
 
-		unquoted := Eval(call.Arguments[0], env)
+		unquoted := runtime.eval(call.Arguments[0], env)
 		return convertObjectToAstNode(unquoted)
 	})
 }
@@ -560,6 +720,12 @@ func convertObjectToAstNode(obj object.Object) ast.Node {
 			Literal: fmt.Sprintf("%d", obj.Value),
 		}
 		return &ast.IntegerLiteral{Token: t, Value: obj.Value}
+	case *object.Float:
+		t := token.Token{
+			Type:    token.FLOAT,
+			Literal: obj.Inspect(),
+		}
+		return &ast.FloatLiteral{Token: t, Value: obj.Value}
 	case *object.Boolean:
 		var t token.Token
 		if obj.Value {
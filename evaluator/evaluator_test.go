@@ -36,6 +36,33 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"2.5", 2.5},
+		{"-2.5", -2.5},
+		{"1.5 + 1.5", 3},
+		{"1 + 0.5", 1.5},
+		{"0.5 * 4", 2},
+		{"7 / 2.0", 3.5},
+		{"1e3 - 1", 999},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(test.input)
+		result, ok := evaluated.(*object.Float)
+		if !ok {
+			t.Errorf("object is not Float. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if result.Value != test.expected {
+			t.Errorf("object has wrong value. got=%g, want=%g", result.Value, test.expected)
+		}
+	}
+}
+
 func testEval(input string) object.Object {
 	lexer := lexer.New(input)
 	parser := parser.New(lexer)
@@ -221,6 +248,22 @@ func TestErrorHandling(t *testing.T) {
 			"-true",
 			"unknown operator: -BOOLEAN",
 		},
+		{
+			"1 / 0",
+			"division by zero",
+		},
+		{
+			"1.5 / 0",
+			"division by zero",
+		},
+		{
+			"1.5 + true",
+			"type mismatch: FLOAT + BOOLEAN",
+		},
+		{
+			"let add = fn(a, b) { a + b }; add(1);",
+			"wrong number of arguments. got=1, want=2",
+		},
 		{
 			"true + false;",
 			"unknown operator: BOOLEAN + BOOLEAN",
@@ -383,6 +426,7 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`len("")`, 0},
 		{`len("four")`, 4},
 		{`len("hello world")`, 11},
+		{`len("日本語")`, 3},
 		{`len(1)`, "argument to `len` not supported, got INTEGER"},
 		{`len("one", "two")`, "wrong number of arguments. got=2, want=1"},
 	}
@@ -571,6 +615,38 @@ func TestHashIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestMemberExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{"a": {"b": 5}}.a.b`, 5},
+		{`let h = {"a": 1}; h.b`, nil},
+		{`math.gcd(12, 18)`, 6},
+		{`math.bogus`, "module math has no member bogus"},
+		{`[1].length`, "member access not supported: ARRAY"},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(test.input)
+		switch expected := test.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errorObject, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("no error object returned. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errorObject.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errorObject.Message)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
 func TestQuote(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -784,7 +860,10 @@ func TestExpandMacros(t *testing.T) {
 
 		env := object.NewEnvironment()
 		DefineMacros(program, env)
-		expanded := ExpandMacros(program, env)
+		expanded, err := ExpandMacros(program, env)
+		if err != nil {
+			t.Fatalf("ExpandMacros returned error: %s", err.Message)
+		}
 
 		if expanded.String() != expected.String() {
 			t.Errorf("not equal. want=%q, got=%q",
@@ -792,3 +871,29 @@ func TestExpandMacros(t *testing.T) {
 		}
 	}
 }
+
+func TestExpandMacrosErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let m = macro() { 1 }; m();", "macro `m` must return a QUOTE, got INTEGER"},
+		{"let m = macro() { let x = 1; }; m();", "macro `m` must return a QUOTE, got nothing"},
+		{"let m = macro() { 1 + true }; m();", "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, test := range tests {
+		program := testParseProgram(test.input)
+
+		env := object.NewEnvironment()
+		DefineMacros(program, env)
+		_, err := ExpandMacros(program, env)
+		if err == nil {
+			t.Errorf("expected error for %q", test.input)
+			continue
+		}
+		if err.Message != test.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", test.input, test.expected, err.Message)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"monkey/object"
+	"os"
+	"path/filepath"
+)
+
+// ErrFileAccessDisabled is the error of the file builtins when the
+// runtime has no file system.
+var ErrFileAccessDisabled = errors.New("file access is disabled")
+
+type dirFS struct {
+	dir string
+}
+
+// DirFS returns a file system of the files under the directory dir. Names
+// cannot leave dir, neither with ".." nor through symbolic links pointing
+// outside of it.
+func DirFS(dir string) object.FileSystem {
+	return dirFS{dir: dir}
+}
+
+func (fsys dirFS) Open(name string) (fs.File, error) {
+	root, err := os.OpenRoot(fsys.dir)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	return root.FS().Open(name)
+}
+
+func (fsys dirFS) WriteFile(name string, data []byte) error {
+	root, err := os.OpenRoot(fsys.dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	file, err := root.OpenFile(filepath.FromSlash(name), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+type readOnlyFS struct {
+	fs.FS
+}
+
+// ReadOnlyFS returns a file system that reads from fsys and refuses all
+// writes with fs.ErrPermission.
+func ReadOnlyFS(fsys fs.FS) object.FileSystem {
+	return readOnlyFS{FS: fsys}
+}
+
+func (fsys readOnlyFS) WriteFile(name string, data []byte) error {
+	return &fs.PathError{Op: "write", Path: name, Err: fs.ErrPermission}
+}
+
+var fileBuiltins = map[string]*object.Builtin{
+	"readFile": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("readFile", args, object.STRING_OBJ); err != nil {
+				return err
+			}
+			if err := checkFileAccess(ctx, stringValue(args[0])); err != nil {
+				return err
+			}
+
+			info, err := fs.Stat(ctx.FS, stringValue(args[0]))
+			if err != nil {
+				return fileError("read", stringValue(args[0]), err)
+			}
+			if err := ctx.CheckAlloc(16 + info.Size()); err != nil {
+				return wrapError(err)
+			}
+
+			contents, err := fs.ReadFile(ctx.FS, stringValue(args[0]))
+			if err != nil {
+				return fileError("read", stringValue(args[0]), err)
+			}
+			return &object.String{Value: string(contents)}
+		},
+	},
+
+	"writeFile": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("writeFile", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+			if err := checkFileAccess(ctx, stringValue(args[0])); err != nil {
+				return err
+			}
+
+			if err := ctx.FS.WriteFile(stringValue(args[0]), []byte(stringValue(args[1]))); err != nil {
+				return fileError("write", stringValue(args[0]), err)
+			}
+			return NULL
+		},
+	},
+
+	"listDir": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("listDir", args, object.STRING_OBJ); err != nil {
+				return err
+			}
+			if err := checkFileAccess(ctx, stringValue(args[0])); err != nil {
+				return err
+			}
+
+			entries, err := fs.ReadDir(ctx.FS, stringValue(args[0]))
+			if err != nil {
+				return fileError("list", stringValue(args[0]), err)
+			}
+			names := make([]object.Object, len(entries))
+			for i, entry := range entries {
+				names[i] = &object.String{Value: entry.Name()}
+			}
+			return &object.Array{Elements: names}
+		},
+	},
+
+	"exists": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("exists", args, object.STRING_OBJ); err != nil {
+				return err
+			}
+			if err := checkFileAccess(ctx, stringValue(args[0])); err != nil {
+				return err
+			}
+
+			_, err := fs.Stat(ctx.FS, stringValue(args[0]))
+			if errors.Is(err, fs.ErrNotExist) {
+				return FALSE
+			}
+			if err != nil {
+				return fileError("stat", stringValue(args[0]), err)
+			}
+			return TRUE
+		},
+	},
+}
+
+// checkFileAccess returns an error if the builtins cannot access any
+// files or name is not a valid path, such as one leaving the root.
+func checkFileAccess(ctx *object.ExecContext, name string) *object.Error {
+	if ctx.FS == nil {
+		return wrapError(ErrFileAccessDisabled)
+	}
+	if !fs.ValidPath(name) {
+		message := fmt.Sprintf("invalid path %q, want a relative path without . or .. elements", name)
+		return &object.Error{Message: message, Err: fs.ErrInvalid}
+	}
+	return nil
+}
+
+// fileError describes err, which happened during op on the file name,
+// in the same words for every file system.
+func fileError(op, name string, err error) *object.Error {
+	cause := err
+	var pathError *fs.PathError
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		cause = fs.ErrNotExist
+	case errors.Is(err, fs.ErrPermission):
+		cause = fs.ErrPermission
+	case errors.As(err, &pathError):
+		cause = pathError.Err
+	}
+	return &object.Error{Message: fmt.Sprintf("cannot %s %q: %s", op, name, cause), Err: err}
+}
@@ -0,0 +1,108 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"monkey/object"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFileBuiltins(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "data"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "data", "a.txt"), []byte("alpha"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("data", filepath.Join(root, "alias")); err != nil {
+		t.Fatal(err)
+	}
+
+	readOnly := ReadOnlyFS(fstest.MapFS{
+		"config.json": {Data: []byte("[1]")},
+	})
+
+	tests := []struct {
+		fsys     object.FileSystem
+		input    string
+		expected string
+	}{
+		{DirFS(root), `readFile("data/a.txt")`, `"alpha"`},
+		{DirFS(root), `writeFile("data/b.txt", "beta"); readFile("data/b.txt")`, `"beta"`},
+		{DirFS(root), `listDir("data")`, `["a.txt", "b.txt"]`},
+		{DirFS(root), `listDir(".")`, `["alias", "data", "link"]`},
+		{DirFS(root), `[exists("data"), exists("data/a.txt"), exists("missing")]`, `[true, true, false]`},
+		{DirFS(root), `readFile("missing")`, `ERROR: cannot read "missing": file does not exist`},
+		{DirFS(root), `writeFile("missing/c.txt", "")`, `ERROR: cannot write "missing/c.txt": file does not exist`},
+		{DirFS(root), `readFile("../secret")`, `ERROR: invalid path "../secret", want a relative path without . or .. elements`},
+		{DirFS(root), `writeFile("/tmp/x", "")`, `ERROR: invalid path "/tmp/x", want a relative path without . or .. elements`},
+		{DirFS(root), `readFile("alias/a.txt")`, `"alpha"`},
+		{DirFS(root), `readFile("link/secret.txt")`, `ERROR: cannot read "link/secret.txt": path escapes from parent`},
+		{DirFS(root), `writeFile("link/pwned.txt", "x")`, `ERROR: cannot write "link/pwned.txt": path escapes from parent`},
+		{DirFS(root), `listDir("link")`, `ERROR: cannot list "link": path escapes from parent`},
+		{DirFS(root), `exists("data/../..")`, `ERROR: invalid path "data/../..", want a relative path without . or .. elements`},
+		{readOnly, `jsonParse(readFile("config.json"))`, `[1]`},
+		{readOnly, `writeFile("config.json", "[]")`, `ERROR: cannot write "config.json": permission denied`},
+		{nil, `readFile("data/a.txt")`, `ERROR: file access is disabled`},
+		{nil, `exists("data")`, `ERROR: file access is disabled`},
+	}
+
+	for _, test := range tests {
+		runtime := NewRuntime()
+		runtime.FS = test.fsys
+
+		evaluated := runtime.Eval(context.Background(), testParseProgram(test.input), object.NewEnvironment())
+		if actual := object.Repr(evaluated); actual != test.expected {
+			t.Errorf("wrong result for %s.\nexpected=%s\ngot=     %s", test.input, test.expected, actual)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(outside, "pwned.txt")); !os.IsNotExist(err) {
+		t.Errorf("file written outside the root through a symbolic link")
+	}
+}
+
+func TestReadFileLimit(t *testing.T) {
+	fsys := ReadOnlyFS(fstest.MapFS{
+		"big.txt":   {Data: make([]byte, 2<<20)},
+		"small.txt": {Data: []byte("small")},
+	})
+
+	tests := []struct {
+		input    string
+		expected error
+	}{
+		{`readFile("big.txt")`, ErrAllocLimit},
+		{`readFile("small.txt")`, nil},
+	}
+
+	for _, test := range tests {
+		runtime := NewRuntime()
+		runtime.FS = fsys
+		runtime.Limits = Limits{MaxAllocBytes: 1 << 20}
+
+		evaluated := runtime.Eval(context.Background(), testParseProgram(test.input), object.NewEnvironment())
+		err, _ := evaluated.(*object.Error)
+		if test.expected == nil {
+			if err != nil {
+				t.Errorf("unexpected error for %s: %s", test.input, err.Message)
+			}
+			continue
+		}
+		if err == nil || !errors.Is(err, test.expected) {
+			t.Errorf("wrong error for %s. expected=%v, got=%s", test.input, test.expected, object.Repr(evaluated))
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package evaluator
+
+import "monkey/object"
+
+var hashBuiltins = map[string]*object.Builtin{
+	"keys": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("keys", args, object.HASH_OBJ); err != nil {
+				return err
+			}
+
+			pairs := args[0].(*object.Hash).SortedPairs()
+			keys := make([]object.Object, len(pairs))
+			for i, pair := range pairs {
+				keys[i] = pair.Key
+			}
+			return &object.Array{Elements: keys}
+		},
+	},
+
+	"values": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("values", args, object.HASH_OBJ); err != nil {
+				return err
+			}
+
+			pairs := args[0].(*object.Hash).SortedPairs()
+			values := make([]object.Object, len(pairs))
+			for i, pair := range pairs {
+				values[i] = pair.Value
+			}
+			return &object.Array{Elements: values}
+		},
+	},
+
+	"entries": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("entries", args, object.HASH_OBJ); err != nil {
+				return err
+			}
+
+			pairs := args[0].(*object.Hash).SortedPairs()
+			entries := make([]object.Object, len(pairs))
+			for i, pair := range pairs {
+				entries[i] = &object.Array{Elements: []object.Object{pair.Key, pair.Value}}
+			}
+			return &object.Array{Elements: entries}
+		},
+	},
+
+	"has": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			if args[0].Type() != object.HASH_OBJ {
+				return newError("argument 1 to `has` must be HASH, got %s", args[0].Type())
+			}
+
+			key, ok := args[1].(object.Hashable)
+			if !ok {
+				return newError("unusable as hash key: %s", args[1].Type())
+			}
+
+			_, ok = args[0].(*object.Hash).Pairs[key.HashKey()]
+			return nativeBoolToBooleanObject(ok)
+		},
+	},
+
+	"delete": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			if args[0].Type() != object.HASH_OBJ {
+				return newError("argument 1 to `delete` must be HASH, got %s", args[0].Type())
+			}
+
+			key, ok := args[1].(object.Hashable)
+			if !ok {
+				return newError("unusable as hash key: %s", args[1].Type())
+			}
+
+			hash := copyHash(args[0].(*object.Hash))
+			delete(hash.Pairs, key.HashKey())
+			return hash
+		},
+	},
+
+	"set": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=3", len(args))
+			}
+			if args[0].Type() != object.HASH_OBJ {
+				return newError("argument 1 to `set` must be HASH, got %s", args[0].Type())
+			}
+
+			key, ok := args[1].(object.Hashable)
+			if !ok {
+				return newError("unusable as hash key: %s", args[1].Type())
+			}
+
+			hash := copyHash(args[0].(*object.Hash))
+			hash.Pairs[key.HashKey()] = object.HashPair{Key: args[1], Value: args[2]}
+			return hash
+		},
+	},
+
+	"merge": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) == 0 {
+				return newError("wrong number of arguments. got=0, want at least 1")
+			}
+
+			merged := &object.Hash{Pairs: make(map[object.HashKey]object.HashPair)}
+			for i, arg := range args {
+				hash, ok := arg.(*object.Hash)
+				if !ok {
+					return newError("argument %d to `merge` must be HASH, got %s", i+1, arg.Type())
+				}
+				for key, pair := range hash.Pairs {
+					merged.Pairs[key] = pair
+				}
+			}
+			return merged
+		},
+	},
+}
+
+func copyHash(hash *object.Hash) *object.Hash {
+	pairs := make(map[object.HashKey]object.HashPair, len(hash.Pairs))
+	for key, pair := range hash.Pairs {
+		pairs[key] = pair
+	}
+	return &object.Hash{Pairs: pairs}
+}
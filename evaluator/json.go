@@ -0,0 +1,176 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"monkey/object"
+	"strconv"
+	"strings"
+)
+
+var jsonBuiltins = map[string]*object.Builtin{
+	"jsonParse": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("jsonParse", args, object.STRING_OBJ); err != nil {
+				return err
+			}
+
+			decoder := json.NewDecoder(strings.NewReader(stringValue(args[0])))
+			decoder.UseNumber()
+
+			var value interface{}
+			if err := decoder.Decode(&value); err != nil {
+				return newError("invalid JSON: %s", err)
+			}
+			if _, err := decoder.Token(); err != io.EOF {
+				return newError("invalid JSON: data after top-level value")
+			}
+			return fromJSON(value)
+		},
+	},
+
+	"jsonStringify": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+
+			indent := ""
+			if len(args) == 2 {
+				switch arg := args[1].(type) {
+				case *object.Integer:
+					if arg.Value < 0 || arg.Value > 10 {
+						return newError("indent %d out of range, want 0 to 10", arg.Value)
+					}
+					indent = strings.Repeat(" ", int(arg.Value))
+				case *object.String:
+					indent = arg.Value
+				default:
+					return newError("argument 2 to `jsonStringify` must be INTEGER or STRING, got %s", arg.Type())
+				}
+			}
+
+			value, err := toJSON(args[0], make(map[object.Object]bool))
+			if err != nil {
+				return err
+			}
+
+			var out bytes.Buffer
+			encoder := json.NewEncoder(&out)
+			encoder.SetEscapeHTML(false)
+			encoder.SetIndent("", indent)
+			if err := encoder.Encode(value); err != nil {
+				return newError("cannot encode JSON: %s", err)
+			}
+			return &object.String{Value: strings.TrimSuffix(out.String(), "\n")}
+		},
+	},
+}
+
+// fromJSON converts a value decoded by encoding/json, with numbers kept as
+// json.Number, to an object. Numbers without a fraction or exponent
+// become integers if they fit.
+func fromJSON(value interface{}) object.Object {
+	switch value := value.(type) {
+	case nil:
+		return NULL
+	case bool:
+		return nativeBoolToBooleanObject(value)
+	case string:
+		return &object.String{Value: value}
+	case json.Number:
+		if integer, err := strconv.ParseInt(string(value), 10, 64); err == nil {
+			return &object.Integer{Value: integer}
+		}
+		float, err := strconv.ParseFloat(string(value), 64)
+		if err != nil {
+			return newError("JSON number %s out of range", value)
+		}
+		return &object.Float{Value: float}
+	case []interface{}:
+		elements := make([]object.Object, len(value))
+		for i, element := range value {
+			elements[i] = fromJSON(element)
+			if isError(elements[i]) {
+				return elements[i]
+			}
+		}
+		return &object.Array{Elements: elements}
+	case map[string]interface{}:
+		pairs := make(map[object.HashKey]object.HashPair, len(value))
+		for key, element := range value {
+			converted := fromJSON(element)
+			if isError(converted) {
+				return converted
+			}
+			str := &object.String{Value: key}
+			pairs[str.HashKey()] = object.HashPair{Key: str, Value: converted}
+		}
+		return &object.Hash{Pairs: pairs}
+	default:
+		return newError("unexpected JSON value %T", value)
+	}
+}
+
+// toJSON converts obj to a value encoding/json encodes the same way.
+// Arrays and hashes being converted are kept in path, to report cycles
+// instead of recursing forever.
+func toJSON(obj object.Object, path map[object.Object]bool) (interface{}, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Null:
+		return nil, nil
+	case *object.Boolean:
+		return obj.Value, nil
+	case *object.Integer:
+		return obj.Value, nil
+	case *object.Float:
+		if math.IsNaN(obj.Value) || math.IsInf(obj.Value, 0) {
+			return nil, newError("cannot encode %s as JSON", obj.Inspect())
+		}
+		// Inspect keeps the fraction of whole floats, so they are decoded
+		// as floats again.
+		return json.Number(obj.Inspect()), nil
+	case *object.String:
+		return obj.Value, nil
+	case *object.Array:
+		if path[obj] {
+			return nil, newError("cannot encode cyclic ARRAY as JSON")
+		}
+		path[obj] = true
+		defer delete(path, obj)
+
+		elements := make([]interface{}, len(obj.Elements))
+		for i, element := range obj.Elements {
+			value, err := toJSON(element, path)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = value
+		}
+		return elements, nil
+	case *object.Hash:
+		if path[obj] {
+			return nil, newError("cannot encode cyclic HASH as JSON")
+		}
+		path[obj] = true
+		defer delete(path, obj)
+
+		pairs := make(map[string]interface{}, len(obj.Pairs))
+		for _, pair := range obj.Pairs {
+			key, ok := pair.Key.(*object.String)
+			if !ok {
+				return nil, newError("cannot encode %s hash key as JSON, keys must be STRING", pair.Key.Type())
+			}
+			value, err := toJSON(pair.Value, path)
+			if err != nil {
+				return nil, err
+			}
+			pairs[key.Value] = value
+		}
+		return pairs, nil
+	default:
+		return nil, newError("cannot encode %s as JSON", obj.Type())
+	}
+}
@@ -0,0 +1,91 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"monkey/object"
+	"reflect"
+	"testing"
+)
+
+func TestJSONBuiltins(t *testing.T) {
+	testBuiltins(t, []builtinTest{
+		{`jsonParse("[1, 2.5, -3e2, true, null, []]")`, `[1, 2.5, -300.0, true, null, []]`},
+		{`jsonParse("{}")`, `{}`},
+		{`jsonParse("[1,")`, "ERROR: invalid JSON: unexpected EOF"},
+		{`jsonParse("1 2")`, "ERROR: invalid JSON: data after top-level value"},
+		{`jsonParse("1 x")`, "ERROR: invalid JSON: data after top-level value"},
+		{`jsonParse("{} ]")`, "ERROR: invalid JSON: data after top-level value"},
+		{`jsonParse("[] garbage")`, "ERROR: invalid JSON: data after top-level value"},
+		{`jsonParse(" [1] ")`, `[1]`},
+		{`jsonParse("1e999")`, "ERROR: JSON number 1e999 out of range"},
+		{`jsonStringify({"b": [1, 2.0], "a": jsonParse("null")})`, `"{\"a\":null,\"b\":[1,2.0]}"`},
+		{`jsonStringify({"a": [1]}, 2)`, `"{\n  \"a\": [\n    1\n  ]\n}"`},
+		{`jsonStringify([1], "--")`, `"[\n--1\n]"`},
+		{`jsonStringify("<&>")`, `"\"<&>\""`},
+		{`jsonStringify([fn(x) { x }])`, "ERROR: cannot encode FUNCTION as JSON"},
+		{`jsonStringify(len)`, "ERROR: cannot encode BUILTIN as JSON"},
+		{`jsonStringify({1: 2})`, "ERROR: cannot encode INTEGER hash key as JSON, keys must be STRING"},
+		{`jsonStringify(1, -1)`, "ERROR: indent -1 out of range, want 0 to 10"},
+	})
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	documents := []string{
+		`null`,
+		`"text with \"quotes\", \\ and é"`,
+		`[1, -2, 3.25, 1e100, 9007199254740993, true, false, null]`,
+		`{"name": "monkey", "tags": ["a", "b"], "nested": {"empty": {}, "list": []}}`,
+		`[{"a": [[1], [2, {"b": null}]]}]`,
+	}
+
+	for _, document := range documents {
+		env := object.NewEnvironment()
+		env.Set("document", &object.String{Value: document})
+
+		program := testParseProgram(`jsonStringify(jsonParse(document))`)
+		evaluated := NewRuntime().Eval(context.Background(), program, env)
+		encoded, ok := evaluated.(*object.String)
+		if !ok {
+			t.Errorf("round trip of %s failed: %s", document, evaluated.Inspect())
+			continue
+		}
+
+		var expected, actual interface{}
+		if err := json.Unmarshal([]byte(document), &expected); err != nil {
+			t.Fatalf("bad test document %s: %s", document, err)
+		}
+		if err := json.Unmarshal([]byte(encoded.Value), &actual); err != nil {
+			t.Errorf("jsonStringify produced invalid JSON %s: %s", encoded.Value, err)
+			continue
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("wrong round trip of %s. got=%s", document, encoded.Value)
+		}
+	}
+}
+
+func TestJSONStringifyCycle(t *testing.T) {
+	cyclic := &object.Array{}
+	cyclic.Elements = []object.Object{&object.Integer{Value: 1}, cyclic}
+
+	shared := &object.Array{Elements: []object.Object{&object.Integer{Value: 1}}}
+
+	tests := []struct {
+		value    object.Object
+		expected string
+	}{
+		{cyclic, "ERROR: cannot encode cyclic ARRAY as JSON"},
+		{&object.Array{Elements: []object.Object{shared, shared}}, "[[1],[1]]"},
+	}
+
+	for _, test := range tests {
+		env := object.NewEnvironment()
+		env.Set("value", test.value)
+
+		evaluated := NewRuntime().Eval(context.Background(), testParseProgram(`jsonStringify(value)`), env)
+		if evaluated.Inspect() != test.expected {
+			t.Errorf("wrong result. expected=%q, got=%q", test.expected, evaluated.Inspect())
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package evaluator
+
+import (
+	"math"
+	"monkey/object"
+)
+
+var mathModule = &object.Module{
+	Name: "math",
+	Members: map[string]object.Object{
+		"pi": &object.Float{Value: math.Pi},
+		"e":  &object.Float{Value: math.E},
+
+		"abs": &object.Builtin{
+			Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+				if err := checkNumbers("math.abs", args, 1); err != nil {
+					return err
+				}
+
+				switch arg := args[0].(type) {
+				case *object.Integer:
+					if arg.Value == math.MinInt64 {
+						return newError("integer overflow in `math.abs`")
+					}
+					if arg.Value < 0 {
+						return &object.Integer{Value: -arg.Value}
+					}
+					return arg
+				default:
+					return &object.Float{Value: math.Abs(floatValue(arg))}
+				}
+			},
+		},
+
+		"min": &object.Builtin{
+			Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+				return extremum("math.min", args, false)
+			},
+		},
+
+		"max": &object.Builtin{
+			Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+				return extremum("math.max", args, true)
+			},
+		},
+
+		"pow": &object.Builtin{
+			Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+				if err := checkNumbers("math.pow", args, 2); err != nil {
+					return err
+				}
+
+				base, isInt := args[0].(*object.Integer)
+				exponent, isIntExponent := args[1].(*object.Integer)
+				if isInt && isIntExponent && exponent.Value >= 0 {
+					return integerPow(base.Value, exponent.Value)
+				}
+				return finite("math.pow", args, math.Pow(floatValue(args[0]), floatValue(args[1])))
+			},
+		},
+
+		"sqrt":  unaryMath("math.sqrt", math.Sqrt),
+		"sin":   unaryMath("math.sin", math.Sin),
+		"cos":   unaryMath("math.cos", math.Cos),
+		"tan":   unaryMath("math.tan", math.Tan),
+		"asin":  unaryMath("math.asin", math.Asin),
+		"acos":  unaryMath("math.acos", math.Acos),
+		"floor": roundingMath("math.floor", math.Floor),
+		"ceil":  roundingMath("math.ceil", math.Ceil),
+		"round": roundingMath("math.round", math.Round),
+
+		// atan(y, x) is the angle of the point (x, y), as atan2 in Go.
+		"atan": &object.Builtin{
+			Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+				if len(args) == 2 {
+					if err := checkNumbers("math.atan", args, 2); err != nil {
+						return err
+					}
+					return finite("math.atan", args, math.Atan2(floatValue(args[0]), floatValue(args[1])))
+				}
+				if err := checkNumbers("math.atan", args, 1); err != nil {
+					return err
+				}
+				return finite("math.atan", args, math.Atan(floatValue(args[0])))
+			},
+		},
+
+		"log": &object.Builtin{
+			Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+				if len(args) == 2 {
+					if err := checkNumbers("math.log", args, 2); err != nil {
+						return err
+					}
+					base := floatValue(args[1])
+					if base <= 0 || base == 1 {
+						return newError("base of `math.log` out of domain, got %s", args[1].Inspect())
+					}
+				} else if err := checkNumbers("math.log", args, 1); err != nil {
+					return err
+				}
+
+				x := floatValue(args[0])
+				if x <= 0 {
+					return newError("argument to `math.log` out of domain, got %s", args[0].Inspect())
+				}
+				if len(args) == 2 {
+					return finite("math.log", args, math.Log(x)/math.Log(floatValue(args[1])))
+				}
+				return finite("math.log", args, math.Log(x))
+			},
+		},
+
+		"gcd": &object.Builtin{
+			Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+				if err := checkArguments("math.gcd", args, object.INTEGER_OBJ, object.INTEGER_OBJ); err != nil {
+					return err
+				}
+
+				a := absUint64(args[0].(*object.Integer).Value)
+				b := absUint64(args[1].(*object.Integer).Value)
+				for b != 0 {
+					a, b = b, a%b
+				}
+				if a > math.MaxInt64 {
+					return newError("integer overflow in `math.gcd`")
+				}
+				return &object.Integer{Value: int64(a)}
+			},
+		},
+
+		"divmod": &object.Builtin{
+			Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+				if err := checkArguments("math.divmod", args, object.INTEGER_OBJ, object.INTEGER_OBJ); err != nil {
+					return err
+				}
+
+				a := args[0].(*object.Integer).Value
+				b := args[1].(*object.Integer).Value
+				if b == 0 {
+					return newError("division by zero")
+				}
+				if a == math.MinInt64 && b == -1 {
+					return newError("integer overflow in `math.divmod`")
+				}
+
+				// Round the quotient down, so the remainder has the sign of b.
+				quotient, remainder := a/b, a%b
+				if remainder != 0 && (remainder < 0) != (b < 0) {
+					quotient -= 1
+					remainder += b
+				}
+				return &object.Array{Elements: []object.Object{
+					&object.Integer{Value: quotient},
+					&object.Integer{Value: remainder},
+				}}
+			},
+		},
+	},
+}
+
+// checkNumbers returns an error unless args are count integers or floats.
+func checkNumbers(name string, args []object.Object, count int) *object.Error {
+	if len(args) != count {
+		return newError("wrong number of arguments. got=%d, want=%d", len(args), count)
+	}
+
+	for i, arg := range args {
+		if isNumber(arg) {
+			continue
+		}
+		if count == 1 {
+			return newError("argument to `%s` must be INTEGER or FLOAT, got %s", name, arg.Type())
+		}
+		return newError("argument %d to `%s` must be INTEGER or FLOAT, got %s", i+1, name, arg.Type())
+	}
+
+	return nil
+}
+
+// finite returns value as a float, or an error if it is NaN or infinite,
+// which happens for arguments outside the domain of a function or results
+// too large for a float.
+func finite(name string, args []object.Object, value float64) object.Object {
+	if math.IsNaN(value) {
+		printed := make([]string, len(args))
+		for i, arg := range args {
+			printed[i] = arg.Inspect()
+		}
+		if len(args) == 1 {
+			return newError("argument to `%s` out of domain, got %s", name, printed[0])
+		}
+		return newError("arguments to `%s` out of domain, got %v", name, printed)
+	}
+	if math.IsInf(value, 0) {
+		return newError("result of `%s` out of range", name)
+	}
+	return &object.Float{Value: value}
+}
+
+func unaryMath(name string, fn func(float64) float64) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkNumbers(name, args, 1); err != nil {
+				return err
+			}
+			return finite(name, args, fn(floatValue(args[0])))
+		},
+	}
+}
+
+// roundingMath returns a builtin rounding a float to an integer with fn.
+// Integers are returned unchanged.
+func roundingMath(name string, fn func(float64) float64) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkNumbers(name, args, 1); err != nil {
+				return err
+			}
+			if integer, ok := args[0].(*object.Integer); ok {
+				return integer
+			}
+			return floatToInteger(fn(floatValue(args[0])))
+		},
+	}
+}
+
+// extremum returns the smallest argument, or the largest if largest is
+// set. The result is an integer if all arguments are.
+func extremum(name string, args []object.Object, largest bool) object.Object {
+	if len(args) == 0 {
+		return newError("wrong number of arguments. got=0, want at least 1")
+	}
+
+	result := args[0]
+	allIntegers := true
+	for i, arg := range args {
+		if !isNumber(arg) {
+			return newError("argument %d to `%s` must be INTEGER or FLOAT, got %s", i+1, name, arg.Type())
+		}
+		if arg.Type() != object.INTEGER_OBJ {
+			allIntegers = false
+		}
+
+		before := compareObjects(arg, result)
+		if largest {
+			before = compareObjects(result, arg)
+		}
+		if isTruthy(before) {
+			result = arg
+		}
+	}
+
+	if !allIntegers {
+		return &object.Float{Value: floatValue(result)}
+	}
+	return result
+}
+
+func integerPow(base, exponent int64) object.Object {
+	result := int64(1)
+	for exponent > 0 {
+		if exponent&1 == 1 {
+			if !multiplyFits(result, base) {
+				return newError("integer overflow in `math.pow`")
+			}
+			result *= base
+		}
+		exponent >>= 1
+		if exponent > 0 {
+			if !multiplyFits(base, base) {
+				return newError("integer overflow in `math.pow`")
+			}
+			base *= base
+		}
+	}
+	return &object.Integer{Value: result}
+}
+
+// multiplyFits reports whether a * b does not overflow an int64.
+func multiplyFits(a, b int64) bool {
+	if a == 0 || b == 0 {
+		return true
+	}
+	product := a * b
+	return product/b == a && !(a == -1 && b == math.MinInt64) && !(b == -1 && a == math.MinInt64)
+}
+
+func absUint64(value int64) uint64 {
+	if value < 0 {
+		return uint64(-(value + 1)) + 1
+	}
+	return uint64(value)
+}
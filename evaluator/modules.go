@@ -0,0 +1,79 @@
+package evaluator
+
+import (
+	"io/fs"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"path"
+	"strings"
+)
+
+// importModule returns the module of the file name, whose top-level
+// bindings become its members. The file is read from the file system of
+// the runtime, relative to the directory of the importing module or to
+// ImportDir for the program itself, and is evaluated in an environment of
+// its own the first time it is imported.
+func (runtime *Runtime) importModule(name string) object.Object {
+	dir := runtime.ImportDir
+	if len(runtime.importing) > 0 {
+		dir = path.Dir(runtime.importing[len(runtime.importing)-1])
+	}
+	if dir != "" {
+		name = path.Join(dir, name)
+	}
+	if err := checkFileAccess(runtime.execContext(), name); err != nil {
+		return err
+	}
+
+	if module, ok := runtime.modules[name]; ok {
+		return module
+	}
+	for i, importing := range runtime.importing {
+		if importing == name {
+			return newError("import cycle: %s -> %s", strings.Join(runtime.importing[i:], " -> "), name)
+		}
+	}
+
+	source, err := fs.ReadFile(runtime.FS, name)
+	if err != nil {
+		return fileError("import", name, err)
+	}
+
+	p := parser.New(lexer.New(string(source)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return newError("cannot import %q: %s", name, strings.Join(p.Errors(), "; "))
+	}
+
+	macroEnv := object.NewEnvironment()
+	DefineMacros(program, macroEnv)
+	expanded, expandErr := runtime.expandMacros(program, macroEnv)
+	if expandErr != nil {
+		return &object.Error{Message: name + ": " + expandErr.Message, Err: expandErr.Err}
+	}
+
+	runtime.importing = append(runtime.importing, name)
+	env := object.NewEnvironment()
+	evaluated := runtime.eval(expanded, env)
+	runtime.importing = runtime.importing[:len(runtime.importing)-1]
+
+	if err, ok := evaluated.(*object.Error); ok {
+		return &object.Error{Message: name + ": " + err.Message, Err: err.Err}
+	}
+
+	members := make(map[string]object.Object)
+	for _, member := range env.Names() {
+		members[member], _ = env.Get(member)
+	}
+	module := &object.Module{
+		Name:    strings.TrimSuffix(path.Base(name), path.Ext(name)),
+		Members: members,
+	}
+
+	if runtime.modules == nil {
+		runtime.modules = make(map[string]*object.Module)
+	}
+	runtime.modules[name] = module
+	return module
+}
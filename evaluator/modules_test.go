@@ -0,0 +1,95 @@
+package evaluator
+
+import (
+	"context"
+	"monkey/object"
+	"testing"
+	"testing/fstest"
+)
+
+func TestImport(t *testing.T) {
+	fsys := ReadOnlyFS(fstest.MapFS{
+		"lib/util.mk": {Data: []byte(`
+import "strings.mk" as strings
+let double = fn(x) { x * 2 };
+let shout = fn(s) { strings.exclaim(upper(s)) };
+let unless = macro(cond, then) { quote(if (!(unquote(cond))) { unquote(then) }) };
+let checked = unless(false, 1);
+`)},
+		"lib/strings.mk": {Data: []byte(`let exclaim = fn(s) { s + "!" };`)},
+		"counter.mk":     {Data: []byte(`let count = len(readFile("count.txt"));`)},
+		"count.txt":      {Data: []byte("xxx")},
+		"a.mk":           {Data: []byte(`import "b.mk" as b`)},
+		"b.mk":           {Data: []byte(`import "a.mk" as a`)},
+		"self.mk":        {Data: []byte(`let me = import("self.mk");`)},
+		"broken.mk":      {Data: []byte(`let = 1;`)},
+		"failing.mk":     {Data: []byte(`let x = 1 + true;`)},
+		"up.mk":          {Data: []byte(`import "../secret.mk" as secret`)},
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "lib/util.mk" as util; util.double(21)`, `42`},
+		{`import "lib/util.mk" as util; util.shout("hi")`, `"HI!"`},
+		{`let util = import("lib/util.mk"); [util.checked, util.strings.exclaim("a")]`, `[1, "a!"]`},
+		{`import "lib/util.mk" as util; util`, `module util`},
+		{`let name = "lib/util"; import(name + ".mk").double(2)`, `4`},
+		{`import "lib/util.mk" as util; util.missing`, "ERROR: module util has no member missing"},
+		{`import "lib/util.mk" as util; unless`, "ERROR: identifier not found: unless"},
+		{`import "lib/util.mk" as util; strings`, "ERROR: identifier not found: strings"},
+		{`import("counter.mk").count`, `3`},
+		{`import "a.mk" as a`, "ERROR: a.mk: b.mk: import cycle: a.mk -> b.mk -> a.mk"},
+		{`import("self.mk")`, "ERROR: self.mk: import cycle: self.mk -> self.mk"},
+		{`import "broken.mk" as broken`, `ERROR: cannot import "broken.mk": expected next token to be IDENT, got = instead; no prefix parse function for = found`},
+		{`import "failing.mk" as failing`, "ERROR: failing.mk: type mismatch: INTEGER + BOOLEAN"},
+		{`import "missing.mk" as missing`, `ERROR: cannot import "missing.mk": file does not exist`},
+		{`import "up.mk" as up`, `ERROR: up.mk: invalid path "../secret.mk", want a relative path without . or .. elements`},
+		{`import(1)`, "ERROR: argument to `import` must be STRING, got INTEGER"},
+	}
+
+	for _, test := range tests {
+		runtime := NewRuntime()
+		runtime.FS = fsys
+
+		evaluated := runtime.Eval(context.Background(), testParseProgram(test.input), object.NewEnvironment())
+		if actual := object.Repr(evaluated); actual != test.expected {
+			t.Errorf("wrong result for %s.\nexpected=%s\ngot=     %s", test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestImportDir(t *testing.T) {
+	runtime := NewRuntime()
+	runtime.FS = ReadOnlyFS(fstest.MapFS{
+		"sub/main.mk": {Data: []byte(`import "util.mk" as util`)},
+		"sub/util.mk": {Data: []byte(`let answer = 42;`)},
+	})
+	runtime.ImportDir = "sub"
+
+	evaluated := runtime.Eval(context.Background(), testParseProgram(`import("util.mk").answer`), object.NewEnvironment())
+	if evaluated.Inspect() != "42" {
+		t.Errorf("import not relative to ImportDir. got=%s", evaluated.Inspect())
+	}
+}
+
+func TestImportCache(t *testing.T) {
+	runtime := NewRuntime()
+	runtime.FS = ReadOnlyFS(fstest.MapFS{
+		"random.mk": {Data: []byte(`let value = random();`)},
+	})
+	env := object.NewEnvironment()
+
+	first := runtime.Eval(context.Background(), testParseProgram(`import("random.mk")`), env)
+	second := runtime.Eval(context.Background(), testParseProgram(`import("random.mk")`), env)
+	if first != second {
+		t.Errorf("module evaluated twice. got=%s and %s", first.Inspect(), second.Inspect())
+	}
+
+	runtime.FS = nil
+	evaluated := runtime.Eval(context.Background(), testParseProgram(`import("random.mk")`), env)
+	if evaluated.Inspect() != "ERROR: file access is disabled" {
+		t.Errorf("import without file system should fail. got=%s", evaluated.Inspect())
+	}
+}
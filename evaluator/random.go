@@ -0,0 +1,70 @@
+package evaluator
+
+import "monkey/object"
+
+var randomBuiltins = map[string]*object.Builtin{
+	"random": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("random", args); err != nil {
+				return err
+			}
+			return &object.Float{Value: ctx.Rand.Float64()}
+		},
+	},
+
+	"randInt": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("randInt", args, object.INTEGER_OBJ, object.INTEGER_OBJ); err != nil {
+				return err
+			}
+
+			low := args[0].(*object.Integer).Value
+			high := args[1].(*object.Integer).Value
+			// The difference is negative if it overflows.
+			if high-low <= 0 || high <= low {
+				return newError("range [%d, %d) of `randInt` is empty or too large", low, high)
+			}
+			return &object.Integer{Value: low + ctx.Rand.Int63n(high-low)}
+		},
+	},
+
+	"shuffle": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("shuffle", args, object.ARRAY_OBJ); err != nil {
+				return err
+			}
+
+			elements := args[0].(*object.Array).Elements
+			shuffled := make([]object.Object, len(elements))
+			copy(shuffled, elements)
+			ctx.Rand.Shuffle(len(shuffled), func(i, j int) {
+				shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+			})
+			return &object.Array{Elements: shuffled}
+		},
+	},
+
+	"choice": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("choice", args, object.ARRAY_OBJ); err != nil {
+				return err
+			}
+
+			elements := args[0].(*object.Array).Elements
+			if len(elements) == 0 {
+				return newError("argument to `choice` must not be empty")
+			}
+			return elements[ctx.Rand.Intn(len(elements))]
+		},
+	},
+
+	"seed": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("seed", args, object.INTEGER_OBJ); err != nil {
+				return err
+			}
+			ctx.Rand.Seed(args[0].(*object.Integer).Value)
+			return NULL
+		},
+	},
+}
@@ -0,0 +1,143 @@
+package evaluator
+
+import (
+	"monkey/object"
+	"regexp"
+	"strings"
+)
+
+var regexBuiltins = map[string]*object.Builtin{
+	"regex": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("regex", args, object.STRING_OBJ); err != nil {
+				return err
+			}
+
+			re, err := ctx.Compile(stringValue(args[0]))
+			if err != nil {
+				return newError("invalid regular expression: %s", err)
+			}
+			return &object.Regex{Regexp: re}
+		},
+	},
+
+	"match": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			re, err := regexArguments(ctx, "match", args, object.STRING_OBJ)
+			if err != nil {
+				return err
+			}
+
+			str := stringValue(args[1])
+			match := re.FindStringSubmatchIndex(str)
+			if match == nil {
+				return NULL
+			}
+			return matchGroups(str, match)
+		},
+	},
+
+	"matchAll": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			re, err := regexArguments(ctx, "matchAll", args, object.STRING_OBJ)
+			if err != nil {
+				return err
+			}
+
+			str := stringValue(args[1])
+			matches := re.FindAllStringSubmatchIndex(str, -1)
+			elements := make([]object.Object, len(matches))
+			for i, match := range matches {
+				elements[i] = matchGroups(str, match)
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+
+	"replaceRegex": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			re, err := regexArguments(ctx, "replaceRegex", args, object.STRING_OBJ, object.STRING_OBJ)
+			if err != nil {
+				return err
+			}
+
+			str, replacement := stringValue(args[1]), stringValue(args[2])
+			if err := ctx.CheckAlloc(16 + replacedSize(re, str, replacement)); err != nil {
+				return wrapError(err)
+			}
+			return &object.String{Value: re.ReplaceAllString(str, replacement)}
+		},
+	},
+
+	"splitRegex": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			re, err := regexArguments(ctx, "splitRegex", args, object.STRING_OBJ)
+			if err != nil {
+				return err
+			}
+
+			parts := re.Split(stringValue(args[1]), -1)
+			elements := make([]object.Object, len(parts))
+			for i, part := range parts {
+				elements[i] = &object.String{Value: part}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+}
+
+// regexArguments checks that args are a regular expression, given as a
+// REGEX or a STRING to compile, followed by arguments of the given types,
+// and returns the regular expression.
+func regexArguments(ctx *object.ExecContext, name string, args []object.Object, types ...object.ObjectType) (*regexp.Regexp, *object.Error) {
+	if len(args) != len(types)+1 {
+		return nil, newError("wrong number of arguments. got=%d, want=%d", len(args), len(types)+1)
+	}
+	for i, typ := range types {
+		if args[i+1].Type() != typ {
+			return nil, newError("argument %d to `%s` must be %s, got %s", i+2, name, typ, args[i+1].Type())
+		}
+	}
+
+	switch pattern := args[0].(type) {
+	case *object.Regex:
+		return pattern.Regexp, nil
+	case *object.String:
+		re, err := ctx.Compile(pattern.Value)
+		if err != nil {
+			return nil, newError("invalid regular expression: %s", err)
+		}
+		return re, nil
+	default:
+		return nil, newError("argument 1 to `%s` must be REGEX or STRING, got %s", name, pattern.Type())
+	}
+}
+
+// replacedSize returns an upper bound of the length of str with the
+// matches of re replaced by replacement. Every $ of replacement may expand
+// to a group, which is at most as long as the match it is part of.
+func replacedSize(re *regexp.Regexp, str, replacement string) int64 {
+	references := int64(strings.Count(replacement, "$"))
+
+	size := int64(len(str))
+	for _, match := range re.FindAllStringIndex(str, -1) {
+		length := int64(match[1] - match[0])
+		size += int64(len(replacement)) + references*length - length
+	}
+	return size
+}
+
+// matchGroups returns the text of a match and of its groups, given their
+// indexes in str, with null for groups that did not participate.
+func matchGroups(str string, match []int) object.Object {
+	groups := make([]object.Object, len(match)/2)
+	for i := range groups {
+		start, end := match[2*i], match[2*i+1]
+		if start < 0 {
+			groups[i] = NULL
+			continue
+		}
+		groups[i] = &object.String{Value: str[start:end]}
+	}
+	return &object.Array{Elements: groups}
+}
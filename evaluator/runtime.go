@@ -0,0 +1,238 @@
+package evaluator
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"monkey/ast"
+	"monkey/object"
+	"os"
+	"regexp"
+	"time"
+)
+
+// checkInterval is the number of nodes evaluated between two checks of
+// the context.
+const checkInterval = 1024
+
+// maxCachedRegexps bounds the number of compiled regular expressions a
+// runtime keeps for reuse.
+const maxCachedRegexps = 256
+
+// DefaultMaxDepth is the call depth allowed when Limits.MaxDepth is zero.
+// Much deeper recursion would overflow the Go stack.
+const DefaultMaxDepth = 10000
+
+var (
+	ErrDepthLimit = errors.New("maximum call depth exceeded")
+	ErrStepLimit  = errors.New("maximum number of evaluation steps exceeded")
+	ErrAllocLimit = errors.New("maximum allocation exceeded")
+)
+
+// Limits bound the resources a single call to Runtime.Eval or
+// Runtime.ExpandMacros may use. A zero field means no limit, except for
+// MaxDepth, where it means DefaultMaxDepth.
+type Limits struct {
+	// MaxDepth is the maximum number of nested function calls.
+	MaxDepth int
+	// MaxSteps is the maximum number of evaluated nodes.
+	MaxSteps int
+	// MaxAllocBytes is the approximate maximum number of bytes allocated
+	// for strings, arrays and hashes.
+	MaxAllocBytes int
+}
+
+// Runtime holds the state of evaluation that outlives a single call to
+// Eval, such as the context that can stop it and its resource limits.
+type Runtime struct {
+	Limits Limits
+
+	// Stdout, Stderr and Stdin are the streams of the I/O builtins. Stdin
+	// is buffered on first use and must not be changed afterwards.
+	Stdout io.Writer
+	Stderr io.Writer
+	Stdin  io.Reader
+
+	// Rand is the source of the random builtins. Setting it to a
+	// generator with a fixed seed makes runs reproducible.
+	Rand *rand.Rand
+
+	// Clock is the source of time of the time builtins.
+	Clock object.Clock
+
+	// FS holds the files the file builtins may access. If it is nil, as
+	// by default, they fail.
+	FS object.FileSystem
+
+	// ImportDir is the directory of FS that imports of the evaluated
+	// program are relative to, usually the directory of its file. It
+	// defaults to the root of FS.
+	ImportDir string
+
+	ctx   context.Context
+	depth int
+	steps int
+	bytes int
+
+	exec    *object.ExecContext
+	stdin   *bufio.Reader
+	regexps map[string]*regexp.Regexp
+
+	// modules caches the imported modules by path, and importing holds
+	// the paths of the modules being evaluated, innermost last.
+	modules   map[string]*object.Module
+	importing []string
+}
+
+// NewRuntime returns a Runtime without limits whose I/O builtins use the
+// standard streams of the process, whose random builtins are seeded with
+// the current time and whose time builtins use SystemClock.
+func NewRuntime() *Runtime {
+	return &Runtime{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+		Stdin:  os.Stdin,
+		Rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		Clock:  SystemClock,
+		ctx:    context.Background(),
+	}
+}
+
+// Eval evaluates node in env. If ctx is canceled or its deadline passes,
+// evaluation stops with an error wrapping ctx.Err(), and if it exceeds
+// the limits of runtime, with one wrapping ErrDepthLimit, ErrStepLimit or
+// ErrAllocLimit.
+func (runtime *Runtime) Eval(ctx context.Context, node ast.Node, env *object.Environment) object.Object {
+	runtime.ctx = ctx
+	runtime.steps = 0
+	runtime.bytes = 0
+	runtime.exec = nil
+
+	if err := ctx.Err(); err != nil {
+		return wrapError(err)
+	}
+	return runtime.eval(node, env)
+}
+
+// Eval evaluates node in env with a new Runtime that cannot be canceled.
+func Eval(node ast.Node, env *object.Environment) object.Object {
+	return NewRuntime().Eval(context.Background(), node, env)
+}
+
+// step counts an evaluated node and returns an error if evaluation has to
+// stop.
+func (runtime *Runtime) step() *object.Error {
+	runtime.steps += 1
+	if runtime.Limits.MaxSteps > 0 && runtime.steps > runtime.Limits.MaxSteps {
+		return wrapError(ErrStepLimit)
+	}
+
+	if runtime.steps%checkInterval != 0 {
+		return nil
+	}
+	if err := runtime.ctx.Err(); err != nil {
+		return wrapError(err)
+	}
+	return nil
+}
+
+// enter records a function call and returns an error if it nests too
+// deeply. Every successful call must be paired with a call to leave.
+func (runtime *Runtime) enter() *object.Error {
+	maxDepth := runtime.Limits.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = DefaultMaxDepth
+	}
+
+	if runtime.depth >= maxDepth {
+		return wrapError(ErrDepthLimit)
+	}
+	runtime.depth += 1
+	return nil
+}
+
+func (runtime *Runtime) leave() {
+	runtime.depth -= 1
+}
+
+// alloc accounts for the memory held by the newly created obj and returns
+// obj, or an error if the allocation limit is exceeded. Only the object
+// itself is counted, not the elements it refers to.
+func (runtime *Runtime) alloc(obj object.Object) object.Object {
+	switch obj := obj.(type) {
+	case *object.String:
+		runtime.bytes += 16 + len(obj.Value)
+	case *object.Array:
+		runtime.bytes += 24 + 16*len(obj.Elements)
+	case *object.Hash:
+		runtime.bytes += 48 + 64*len(obj.Pairs)
+	default:
+		return obj
+	}
+
+	if runtime.Limits.MaxAllocBytes > 0 && runtime.bytes > runtime.Limits.MaxAllocBytes {
+		return wrapError(ErrAllocLimit)
+	}
+	return obj
+}
+
+// checkAlloc returns ErrAllocLimit if allocating size more bytes would
+// exceed the allocation limit. Unlike alloc, it accounts for nothing.
+func (runtime *Runtime) checkAlloc(size int64) error {
+	maxBytes := int64(runtime.Limits.MaxAllocBytes)
+	if maxBytes > 0 && size > maxBytes-int64(runtime.bytes) {
+		return ErrAllocLimit
+	}
+	return nil
+}
+
+// execContext returns the context passed to builtins.
+func (runtime *Runtime) execContext() *object.ExecContext {
+	if runtime.exec != nil {
+		return runtime.exec
+	}
+
+	// Keep the buffer of Stdin between evaluations, so input read ahead
+	// by one is not lost to the next.
+	if runtime.stdin == nil {
+		runtime.stdin = bufio.NewReader(runtime.Stdin)
+	}
+
+	runtime.exec = &object.ExecContext{
+		Context:    runtime.ctx,
+		Stdout:     runtime.Stdout,
+		Stderr:     runtime.Stderr,
+		Stdin:      runtime.stdin,
+		Rand:       runtime.Rand,
+		Clock:      runtime.Clock,
+		FS:         runtime.FS,
+		Apply:      runtime.applyFunction,
+		Compile:    runtime.compile,
+		CheckAlloc: runtime.checkAlloc,
+	}
+	return runtime.exec
+}
+
+// compile compiles pattern or returns it from the cache of the runtime,
+// which is emptied once it holds maxCachedRegexps patterns.
+func (runtime *Runtime) compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := runtime.regexps[pattern]; ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if runtime.regexps == nil || len(runtime.regexps) >= maxCachedRegexps {
+		runtime.regexps = make(map[string]*regexp.Regexp)
+	}
+	runtime.regexps[pattern] = re
+	return re, nil
+}
+
+func wrapError(err error) *object.Error {
+	return &object.Error{Message: err.Error(), Err: err}
+}
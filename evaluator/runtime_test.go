@@ -0,0 +1,79 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"monkey/object"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		input    string
+		limits   Limits
+		expected error
+	}{
+		{"let f = fn(n) { f(n + 1) + 1 }; f(0)", Limits{}, ErrDepthLimit},
+		{"let f = fn(n) { if (n == 0) { 0 } else { f(n - 1) + 1 } }; f(50)", Limits{MaxDepth: 20}, ErrDepthLimit},
+		{"let f = fn(n) { if (n == 0) { 0 } else { f(n - 1) + 1 } }; f(50)", Limits{MaxDepth: 100}, nil},
+		{"let f = fn(n) { if (n == 0) { 0 } else { f(n - 1) + 1 } }; f(50)", Limits{MaxSteps: 100}, ErrStepLimit},
+		{"let f = fn(n) { if (n == 0) { 0 } else { f(n - 1) + 1 } }; f(50)", Limits{MaxSteps: 10000}, nil},
+		{`let f = fn(s, n) { if (n == 0) { s } else { f(s + s, n - 1) } }; f("ab", 20)`, Limits{MaxAllocBytes: 1 << 20}, ErrAllocLimit},
+		{`let f = fn(a, n) { if (n == 0) { a } else { f(push(a, n), n - 1) } }; len(f([], 1000))`, Limits{MaxAllocBytes: 1 << 16}, ErrAllocLimit},
+		{`let f = fn(a, n) { if (n == 0) { a } else { f(push(a, n), n - 1) } }; len(f([], 10))`, Limits{MaxAllocBytes: 1 << 16}, nil},
+		{`repeat("ab", 500000000)`, Limits{MaxAllocBytes: 1 << 20}, ErrAllocLimit},
+		{`repeat("ab", 1000)`, Limits{MaxAllocBytes: 1 << 20}, nil},
+		{`let s = repeat("a", 500000); replace(s, "a", s)`, Limits{MaxAllocBytes: 1 << 20}, ErrAllocLimit},
+		{`let s = repeat("a", 1000); replace(s, "a", "bb")`, Limits{MaxAllocBytes: 1 << 20}, nil},
+		{`let s = repeat("a", 500000); join([s, s, s], s)`, Limits{MaxAllocBytes: 1 << 20}, ErrAllocLimit},
+		{`join(["a", "b", "c"], ", ")`, Limits{MaxAllocBytes: 1 << 20}, nil},
+		{`let s = repeat("a", 500000); replaceRegex("a", s, s)`, Limits{MaxAllocBytes: 1 << 20}, ErrAllocLimit},
+		{`replaceRegex("(a+)", repeat("ab", 1000), "<$1>")`, Limits{MaxAllocBytes: 1 << 20}, nil},
+		{`range(134217727)`, Limits{MaxAllocBytes: 1 << 20}, ErrAllocLimit},
+		{`len(range(1000))`, Limits{MaxAllocBytes: 1 << 20}, nil},
+	}
+
+	for _, test := range tests {
+		runtime := NewRuntime()
+		runtime.Limits = test.limits
+
+		evaluated := runtime.Eval(context.Background(), testParseProgram(test.input), object.NewEnvironment())
+
+		err, _ := evaluated.(*object.Error)
+		if test.expected == nil {
+			if err != nil {
+				t.Errorf("unexpected error for %q with %+v: %s", test.input, test.limits, err.Message)
+			}
+			continue
+		}
+		if err == nil || !errors.Is(err, test.expected) {
+			t.Errorf("wrong error for %q with %+v. expected=%v, got=%+v", test.input, test.limits, test.expected, evaluated)
+		}
+	}
+}
+
+func TestRegexCache(t *testing.T) {
+	runtime := NewRuntime()
+	env := object.NewEnvironment()
+
+	first := runtime.Eval(context.Background(), testParseProgram(`regex("a+")`), env)
+	second := runtime.Eval(context.Background(), testParseProgram(`regex("a+")`), env)
+
+	if first.(*object.Regex).Regexp != second.(*object.Regex).Regexp {
+		t.Errorf("pattern compiled twice")
+	}
+}
+
+func TestEvalCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	runtime := NewRuntime()
+	evaluated := runtime.Eval(ctx, testParseProgram("1 + 2"), object.NewEnvironment())
+	if err, ok := evaluated.(*object.Error); !ok || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled error, got=%+v", evaluated)
+	}
+
+	evaluated = runtime.Eval(context.Background(), testParseProgram("1 + 2"), object.NewEnvironment())
+	testIntegerObject(t, evaluated, 3)
+}
@@ -0,0 +1,258 @@
+package evaluator
+
+import (
+	"monkey/object"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxStringLength is the length in bytes of the longest string repeat
+// builds, so a huge count fails even without an allocation limit.
+const maxStringLength = 1 << 30
+
+var stringBuiltins = map[string]*object.Builtin{
+	"split": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("split", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+
+			parts := strings.Split(stringValue(args[0]), stringValue(args[1]))
+			elements := make([]object.Object, len(parts))
+			for i, part := range parts {
+				elements[i] = &object.String{Value: part}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+
+	"join": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("join", args, object.ARRAY_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+
+			elements := args[0].(*object.Array).Elements
+			separator := stringValue(args[1])
+			parts := make([]string, len(elements))
+			size := int64(0)
+			for i, element := range elements {
+				str, ok := element.(*object.String)
+				if !ok {
+					return newError("argument 1 to `join` must be ARRAY of STRING, got %s at index %d",
+						element.Type(), i)
+				}
+				parts[i] = str.Value
+				size += int64(len(str.Value))
+			}
+			if len(parts) > 1 {
+				size += int64(len(parts)-1) * int64(len(separator))
+			}
+			if err := ctx.CheckAlloc(16 + size); err != nil {
+				return wrapError(err)
+			}
+			return &object.String{Value: strings.Join(parts, separator)}
+		},
+	},
+
+	"trim": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("trim", args, object.STRING_OBJ); err != nil {
+				return err
+			}
+			return &object.String{Value: strings.TrimSpace(stringValue(args[0]))}
+		},
+	},
+
+	"upper": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("upper", args, object.STRING_OBJ); err != nil {
+				return err
+			}
+			return &object.String{Value: strings.ToUpper(stringValue(args[0]))}
+		},
+	},
+
+	"lower": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("lower", args, object.STRING_OBJ); err != nil {
+				return err
+			}
+			return &object.String{Value: strings.ToLower(stringValue(args[0]))}
+		},
+	},
+
+	"replace": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("replace", args, object.STRING_OBJ, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+			str, old, replacement := stringValue(args[0]), stringValue(args[1]), stringValue(args[2])
+			size := int64(len(str)) + int64(strings.Count(str, old))*int64(len(replacement)-len(old))
+			if err := ctx.CheckAlloc(16 + size); err != nil {
+				return wrapError(err)
+			}
+			return &object.String{Value: strings.ReplaceAll(str, old, replacement)}
+		},
+	},
+
+	"contains": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("contains", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(strings.Contains(stringValue(args[0]), stringValue(args[1])))
+		},
+	},
+
+	"startsWith": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("startsWith", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(strings.HasPrefix(stringValue(args[0]), stringValue(args[1])))
+		},
+	},
+
+	"endsWith": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("endsWith", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(strings.HasSuffix(stringValue(args[0]), stringValue(args[1])))
+		},
+	},
+
+	"indexOf": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("indexOf", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+				return err
+			}
+
+			str := stringValue(args[0])
+			index := strings.Index(str, stringValue(args[1]))
+			if index < 0 {
+				return &object.Integer{Value: -1}
+			}
+			return &object.Integer{Value: int64(utf8.RuneCountInString(str[:index]))}
+		},
+	},
+
+	"substr": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) == 2 {
+				if err := checkArguments("substr", args, object.STRING_OBJ, object.INTEGER_OBJ); err != nil {
+					return err
+				}
+			} else if err := checkArguments("substr", args, object.STRING_OBJ, object.INTEGER_OBJ, object.INTEGER_OBJ); err != nil {
+				return err
+			}
+
+			runes := []rune(stringValue(args[0]))
+			start := args[1].(*object.Integer).Value
+			if start < 0 || start > int64(len(runes)) {
+				return newError("start %d out of range for string of length %d", start, len(runes))
+			}
+
+			end := int64(len(runes))
+			if len(args) == 3 {
+				length := args[2].(*object.Integer).Value
+				if length < 0 {
+					return newError("negative length %d in call to `substr`", length)
+				}
+				end = start + min(length, end-start)
+			}
+
+			return &object.String{Value: string(runes[start:end])}
+		},
+	},
+
+	"repeat": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if err := checkArguments("repeat", args, object.STRING_OBJ, object.INTEGER_OBJ); err != nil {
+				return err
+			}
+
+			str := stringValue(args[0])
+			count := args[1].(*object.Integer).Value
+			if count < 0 {
+				return newError("negative count %d in call to `repeat`", count)
+			}
+			if len(str) > 0 && count > maxStringLength/int64(len(str)) {
+				return newError("result of `repeat` too long")
+			}
+			if err := ctx.CheckAlloc(16 + count*int64(len(str))); err != nil {
+				return wrapError(err)
+			}
+			return &object.String{Value: strings.Repeat(str, int(count))}
+		},
+	},
+
+	"format": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) == 0 {
+				return newError("wrong number of arguments. got=0, want at least 1")
+			}
+			if args[0].Type() != object.STRING_OBJ {
+				return newError("argument 1 to `format` must be STRING, got %s", args[0].Type())
+			}
+			return formatString(stringValue(args[0]), args[1:])
+		},
+	},
+}
+
+func stringValue(obj object.Object) string {
+	return obj.(*object.String).Value
+}
+
+// formatString substitutes args for the verbs in format: %d for an
+// integer, %s for the printed form of any value, %v for its
+// representation and %% for a percent sign.
+func formatString(format string, args []object.Object) object.Object {
+	var out strings.Builder
+
+	next := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			out.WriteByte(format[i])
+			continue
+		}
+
+		i += 1
+		if i == len(format) {
+			return newError("format ends with %%")
+		}
+
+		verb := format[i]
+		if verb == '%' {
+			out.WriteByte('%')
+			continue
+		}
+		if next == len(args) {
+			return newError("missing argument for %%%c in format", verb)
+		}
+		arg := args[next]
+		next += 1
+
+		switch verb {
+		case 'd':
+			integer, ok := arg.(*object.Integer)
+			if !ok {
+				return newError("%%d in format needs INTEGER, got %s", arg.Type())
+			}
+			out.WriteString(integer.Inspect())
+		case 's':
+			out.WriteString(arg.Inspect())
+		case 'v':
+			out.WriteString(object.Repr(arg))
+		default:
+			return newError("unknown verb %%%c in format", verb)
+		}
+	}
+
+	if next < len(args) {
+		return newError("too many arguments for format. got=%d, want=%d", len(args), next)
+	}
+
+	return &object.String{Value: out.String()}
+}
@@ -0,0 +1,146 @@
+package evaluator
+
+import (
+	"monkey/ast"
+	"monkey/object"
+)
+
+// tailCall is the result of evaluating a call to a function in tail
+// position. applyFunction makes the call in a loop instead of nesting it,
+// so tail recursion runs in constant Go stack space. It never escapes
+// applyFunction.
+type tailCall struct {
+	fn   *object.Function
+	args []object.Object
+}
+
+func (call *tailCall) Type() object.ObjectType { return "TAIL_CALL" }
+func (call *tailCall) Inspect() string         { return "tail call" }
+
+// evalTail evaluates node, the body of a function or part of it in tail
+// position. Calls to functions in tail position are returned as a
+// *tailCall instead of being made.
+func (runtime *Runtime) evalTail(node ast.Node, env *object.Environment) object.Object {
+	switch node.(type) {
+	case *ast.BlockStatement, *ast.ExpressionStatement, *ast.ReturnStatement, *ast.IfExpression, *ast.CallExpression:
+		if err := runtime.step(); err != nil {
+			return err
+		}
+	}
+
+	switch node := node.(type) {
+	case *ast.BlockStatement:
+		if len(node.Statements) == 0 {
+			return nil
+		}
+
+		last := len(node.Statements) - 1
+		for _, statement := range node.Statements[:last] {
+			if result := runtime.evalReturns(statement, env); isReturn(result) {
+				return result
+			}
+		}
+		return runtime.evalTail(node.Statements[last], env)
+
+	case *ast.ExpressionStatement:
+		return runtime.evalTail(node.Expression, env)
+
+	case *ast.ReturnStatement:
+		val := runtime.evalTail(node.ReturnValue, env)
+		if isReturn(val) {
+			return val
+		}
+		return &object.ReturnValue{Value: val}
+
+	case *ast.IfExpression:
+		condition := runtime.eval(node.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+
+		if isTruthy(condition) {
+			return runtime.evalTail(node.Consequence, env)
+		} else if node.Alternative != nil {
+			return runtime.evalTail(node.Alternative, env)
+		} else {
+			return NULL
+		}
+
+	case *ast.CallExpression:
+		if node.Function.TokenLiteral() == "quote" {
+			return runtime.quote(node.Arguments[0], env)
+		}
+
+		function := runtime.eval(node.Function, env)
+		if isError(function) {
+			return function
+		}
+		args := runtime.evalExpressions(node.Arguments, env)
+		if len(args) == 1 && isError(args[0]) {
+			return args[0]
+		}
+
+		if fn, ok := function.(*object.Function); ok {
+			return &tailCall{fn: fn, args: args}
+		}
+		return runtime.applyFunction(function, args)
+
+	default:
+		return runtime.eval(node, env)
+	}
+}
+
+// evalReturns evaluates a statement of a function body whose value is
+// discarded. Calls in return statements are still in tail position, also
+// when the return statement is nested in the branches of an if.
+func (runtime *Runtime) evalReturns(node ast.Statement, env *object.Environment) object.Object {
+	switch node := node.(type) {
+	case *ast.ReturnStatement:
+		return runtime.evalTail(node, env)
+
+	case *ast.ExpressionStatement:
+		ifExpression, ok := node.Expression.(*ast.IfExpression)
+		if !ok {
+			return runtime.eval(node, env)
+		}
+
+		if err := runtime.step(); err != nil {
+			return err
+		}
+
+		condition := runtime.eval(ifExpression.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+
+		var branch *ast.BlockStatement
+		if isTruthy(condition) {
+			branch = ifExpression.Consequence
+		} else if ifExpression.Alternative != nil {
+			branch = ifExpression.Alternative
+		} else {
+			return NULL
+		}
+
+		var result object.Object
+		for _, statement := range branch.Statements {
+			if result = runtime.evalReturns(statement, env); isReturn(result) {
+				return result
+			}
+		}
+		return result
+
+	default:
+		return runtime.eval(node, env)
+	}
+}
+
+// isReturn reports whether obj ends the evaluation of a function body.
+func isReturn(obj object.Object) bool {
+	switch obj.(type) {
+	case *tailCall, *object.ReturnValue, *object.Error:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,51 @@
+package evaluator
+
+import (
+	"context"
+	"monkey/object"
+	"testing"
+)
+
+func TestTailCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let count = fn(n, acc) { if (n == 0) { acc } else { count(n - 1, acc + 1) } }; count(100000, 0)", 100000},
+		{"let count = fn(n, acc) { if (n == 0) { return acc; } return count(n - 1, acc + 1); }; count(100000, 0)", 100000},
+		{"let count = fn(n) { if (n > 0) { return count(n - 1); } n }; count(100000)", 0},
+		{"let even = fn(n) { if (n == 0) { true } else { odd(n - 1) } }; let odd = fn(n) { if (n == 0) { false } else { even(n - 1) } }; even(100001)", false},
+		{"let count = fn(n) { if (n == 0) { 0 } else { let m = n - 1; count(m) } }; count(100000)", 0},
+		{`let reduce = fn(arr, initial, f) { let iter = fn(arr, result) { if (len(arr) == 0) { result } else { iter(rest(arr), f(result, first(arr))) } }; iter(arr, initial) };
+		  let range = fn(n, acc) { if (n == 0) { acc } else { range(n - 1, push(acc, n)) } };
+		  reduce(range(2000, []), 0, fn(sum, x) { sum + x })`, 2001000},
+		{"let f = fn(x) { x }; let g = fn(x) { f(x) + 1 }; g(1)", 2},
+		{"let f = fn() { len([1, 2]) }; f()", 2},
+		{"let f = fn() { 5() }; f()", "not a function: INTEGER"},
+		{"let f = fn(n) { if (n == 0) { nope } else { f(n - 1) } }; f(10)", "identifier not found: nope"},
+		{"let f = fn(n) { f(n + 1) + 1 }; f(0)", "maximum call depth exceeded"},
+	}
+
+	for _, test := range tests {
+		// Without tail calls, the recursive tests would exceed this depth.
+		runtime := NewRuntime()
+		runtime.Limits.MaxDepth = 100
+		evaluated := runtime.Eval(context.Background(), testParseProgram(test.input), object.NewEnvironment())
+
+		switch expected := test.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
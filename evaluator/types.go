@@ -0,0 +1,128 @@
+package evaluator
+
+import (
+	"math"
+	"monkey/object"
+	"strconv"
+	"strings"
+)
+
+var typeBuiltins = map[string]*object.Builtin{
+	"type": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			return &object.String{Value: string(args[0].Type())}
+		},
+	},
+
+	"str": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			if str, ok := args[0].(*object.String); ok {
+				return str
+			}
+			return &object.String{Value: args[0].Inspect()}
+		},
+	},
+
+	"int": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Integer:
+				return arg
+			case *object.Float:
+				return floatToInteger(math.Trunc(arg.Value))
+			case *object.String:
+				value, err := strconv.ParseInt(strings.TrimSpace(arg.Value), 10, 64)
+				if err != nil {
+					return newError("cannot parse %q as INTEGER", arg.Value)
+				}
+				return &object.Integer{Value: value}
+			case *object.Boolean:
+				if arg.Value {
+					return &object.Integer{Value: 1}
+				}
+				return &object.Integer{Value: 0}
+			default:
+				return newError("argument to `int` not supported, got %s", arg.Type())
+			}
+		},
+	},
+
+	"float": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Integer:
+				return &object.Float{Value: float64(arg.Value)}
+			case *object.Float:
+				return arg
+			case *object.String:
+				value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+				if err != nil {
+					return newError("cannot parse %q as FLOAT", arg.Value)
+				}
+				return &object.Float{Value: value}
+			default:
+				return newError("argument to `float` not supported, got %s", arg.Type())
+			}
+		},
+	},
+
+	"bool": {
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			return nativeBoolToBooleanObject(isTruthy(args[0]))
+		},
+	},
+
+	"isNull":     typePredicate(object.NULL_OBJ),
+	"isInteger":  typePredicate(object.INTEGER_OBJ),
+	"isFloat":    typePredicate(object.FLOAT_OBJ),
+	"isNumber":   typePredicate(object.INTEGER_OBJ, object.FLOAT_OBJ),
+	"isString":   typePredicate(object.STRING_OBJ),
+	"isBoolean":  typePredicate(object.BOOLEAN_OBJ),
+	"isArray":    typePredicate(object.ARRAY_OBJ),
+	"isHash":     typePredicate(object.HASH_OBJ),
+	"isFunction": typePredicate(object.FUNCTION_OBJ, object.BUILTIN_OBJ),
+}
+
+// floatToInteger converts a float without a fraction to an integer, or
+// returns an error if it is out of range.
+func floatToInteger(value float64) object.Object {
+	if math.IsNaN(value) || value < math.MinInt64 || value >= math.MaxInt64 {
+		return newError("cannot convert %s to INTEGER", (&object.Float{Value: value}).Inspect())
+	}
+	return &object.Integer{Value: int64(value)}
+}
+
+// typePredicate returns a builtin reporting whether its argument has one
+// of types.
+func typePredicate(types ...object.ObjectType) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(ctx *object.ExecContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			for _, typ := range types {
+				if args[0].Type() == typ {
+					return TRUE
+				}
+			}
+			return FALSE
+		},
+	}
+}
@@ -0,0 +1,659 @@
+// Package format implements canonical formatting of Monkey source code.
+package format
+
+import (
+	"bytes"
+	"errors"
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+	"monkey/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	indentWidth = 4
+	maxWidth    = 80
+)
+
+// Source parses src and returns it in canonical form. Comments are kept
+// next to the statements, list elements, operands and branches they
+// precede or trail, and single blank lines between statements are
+// preserved. Formatting is idempotent: formatting
+// the result again returns it unchanged.
+func Source(src []byte) ([]byte, error) {
+	l := lexer.New(string(src))
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, errors.New(strings.Join(p.Errors(), "\n"))
+	}
+
+	printer := newPrinter(string(src), l.Comments())
+	printer.statements(program.Statements, len(src))
+
+	if printer.out.Len() == 0 {
+		return []byte{}, nil
+	}
+	printer.out.WriteString("\n")
+
+	return printer.out.Bytes(), nil
+}
+
+// Node returns the canonical form of a single node. Since a node carries
+// no comments or layout of its own, only the syntax tree is printed.
+func Node(node ast.Node) string {
+	printer := newPrinter("", nil)
+
+	switch node := node.(type) {
+	case *ast.Program:
+		printer.statements(node.Statements, -1)
+	case *ast.BlockStatement:
+		printer.block(node)
+	case ast.Statement:
+		printer.statement(node, nil)
+	case ast.Expression:
+		printer.expression(node, parser.LOWEST)
+	}
+
+	return printer.out.String()
+}
+
+type printer struct {
+	out    bytes.Buffer
+	indent int
+
+	source   string
+	tokens   []token.Token
+	closers  map[int]int // Offset of an opening bracket to the offset of its closing bracket
+	comments []token.Token
+	next     int // Index of the next comment to print
+}
+
+func newPrinter(source string, comments []token.Token) *printer {
+	printer := &printer{source: source, comments: comments, closers: make(map[int]int)}
+
+	l := lexer.New(source)
+	openers := []int{}
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		printer.tokens = append(printer.tokens, tok)
+
+		switch tok.Type {
+		case token.LPAREN, token.LBRACKET, token.LBRACE:
+			openers = append(openers, tok.Pos.Offset)
+		case token.RPAREN, token.RBRACKET, token.RBRACE:
+			if len(openers) > 0 {
+				printer.closers[openers[len(openers)-1]] = tok.Pos.Offset
+				openers = openers[:len(openers)-1]
+			}
+		}
+	}
+
+	return printer
+}
+
+func (printer *printer) write(s string) {
+	printer.out.WriteString(s)
+}
+
+func (printer *printer) newline() {
+	printer.out.WriteString("\n")
+	printer.out.WriteString(strings.Repeat(" ", printer.indent*indentWidth))
+}
+
+// items tracks the layout of a list of statements and comments.
+type items struct {
+	started     bool // Whether an item of the list has been printed yet
+	previousEnd int  // Source offset of the end of the previous item, or -1
+}
+
+// statements prints a list of statements that ends before the source
+// offset end, together with the comments in between them. An end of -1
+// means the statements have no source to take comments from.
+func (printer *printer) statements(statements []ast.Statement, end int) {
+	list := &items{previousEnd: -1}
+
+	for i, statement := range statements {
+		start := ast.Pos(statement).Offset
+		printer.commentsBefore(start, list)
+		printer.item(start, list)
+
+		var next ast.Statement
+		bound := end
+		if i+1 < len(statements) {
+			next = statements[i+1]
+			bound = ast.Pos(next).Offset
+		}
+
+		printer.statement(statement, next)
+
+		last, ok := printer.lastTokenBefore(bound)
+		if !ok {
+			list.previousEnd = -1
+			continue
+		}
+		list.previousEnd = last.Pos.Offset
+
+		if comment, ok := printer.trailingComment(last, bound); ok {
+			list.previousEnd = comment.Pos.Offset
+		}
+	}
+
+	if end >= 0 {
+		printer.commentsBefore(end, list)
+	}
+}
+
+// trailingComment prints the next comment if it starts before bound on
+// the line of last.
+func (printer *printer) trailingComment(last token.Token, bound int) (token.Token, bool) {
+	if printer.next >= len(printer.comments) {
+		return token.Token{}, false
+	}
+
+	comment := printer.comments[printer.next]
+	if comment.Pos.Offset >= bound || comment.Pos.Line != last.Pos.Line {
+		return token.Token{}, false
+	}
+
+	printer.write(" " + comment.Literal)
+	printer.next += 1
+	return comment, true
+}
+
+// hasCommentBefore reports whether the next comment starts before offset.
+func (printer *printer) hasCommentBefore(offset int) bool {
+	return printer.next < len(printer.comments) && printer.comments[printer.next].Pos.Offset < offset
+}
+
+// commentsBefore prints every pending comment that starts before offset
+// on a line of its own.
+func (printer *printer) commentsBefore(offset int, list *items) {
+	for printer.hasCommentBefore(offset) {
+		comment := printer.comments[printer.next]
+
+		printer.item(comment.Pos.Offset, list)
+		printer.write(comment.Literal)
+
+		printer.next += 1
+		list.previousEnd = comment.Pos.Offset
+	}
+}
+
+// item starts a new line for every item of a list but the first.
+func (printer *printer) item(start int, list *items) {
+	if list.started {
+		printer.separate(list.previousEnd, start)
+	}
+	list.started = true
+}
+
+// separate starts a new line, preceded by a blank line if the source had
+// one between the previous item and the one starting at start.
+func (printer *printer) separate(previousEnd, start int) {
+	if previousEnd >= 0 && previousEnd < start && hasBlankLine(printer.source[previousEnd:start]) {
+		printer.write("\n")
+	}
+	printer.newline()
+}
+
+func hasBlankLine(text string) bool {
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines)-1; i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (printer *printer) lastTokenBefore(offset int) (token.Token, bool) {
+	i := sort.Search(len(printer.tokens), func(i int) bool {
+		return printer.tokens[i].Pos.Offset >= offset
+	})
+	if i == 0 {
+		return token.Token{}, false
+	}
+	return printer.tokens[i-1], true
+}
+
+func (printer *printer) statement(statement ast.Statement, next ast.Statement) {
+	switch statement := statement.(type) {
+	case *ast.LetStatement:
+		printer.write("let " + statement.Name.Value + " = ")
+		printer.operand(statement.Value, parser.LOWEST)
+		printer.write(";")
+	case *ast.ReturnStatement:
+		printer.write("return ")
+		printer.operand(statement.ReturnValue, parser.LOWEST)
+		printer.write(";")
+	case *ast.ImportStatement:
+		printer.write(`import "` + statement.Path.Value + `" as ` + statement.Name.Value + ";")
+	case *ast.ExpressionStatement:
+		printer.expression(statement.Expression, parser.LOWEST)
+		if continuesExpression(next) {
+			printer.write(";")
+		}
+	case *ast.BlockStatement:
+		printer.block(statement)
+	}
+}
+
+// continuesExpression reports whether a statement starts with a token
+// the parser would take as continuing the expression before it, in which
+// case that expression must be terminated with a semicolon.
+func continuesExpression(statement ast.Statement) bool {
+	expressionStatement, ok := statement.(*ast.ExpressionStatement)
+	if !ok {
+		return false
+	}
+
+	switch firstChar(expressionStatement.Expression, parser.LOWEST) {
+	case '(', '[', '-':
+		return true
+	default:
+		return false
+	}
+}
+
+// firstChar returns the first character printed for expression.
+func firstChar(expression ast.Expression, precedence int) byte {
+	if precedenceOf(expression) < precedence {
+		return '('
+	}
+
+	switch expression := expression.(type) {
+	case *ast.InfixExpression:
+		return firstChar(expression.Left, precedenceOf(expression))
+	case *ast.CallExpression:
+		return firstChar(expression.Function, parser.CALL)
+	case *ast.IndexExpression:
+		return firstChar(expression.Left, parser.CALL)
+	case *ast.MemberExpression:
+		return firstChar(expression.Left, parser.CALL)
+	case *ast.PrefixExpression:
+		return expression.Operator[0]
+	case *ast.IntegerLiteral:
+		return strconv.FormatInt(expression.Value, 10)[0]
+	case *ast.FloatLiteral:
+		return formatFloat(expression)[0]
+	case *ast.ArrayLiteral:
+		return '['
+	case *ast.HashLiteral:
+		return '{'
+	case *ast.StringLiteral:
+		return '"'
+	default:
+		return expression.TokenLiteral()[0]
+	}
+}
+
+// formatFloat returns the source of a float literal, which may have been
+// built without a token by a macro.
+func formatFloat(literal *ast.FloatLiteral) string {
+	if literal.Token.Literal != "" && literal.Value >= 0 {
+		return literal.Token.Literal
+	}
+
+	formatted := strconv.FormatFloat(literal.Value, 'g', -1, 64)
+	if !strings.ContainsAny(formatted, ".e") {
+		formatted += ".0"
+	}
+	return formatted
+}
+
+// precedenceOf returns how tightly a printed expression binds. Calls and
+// index expressions share a level since either may follow the other
+// without parentheses.
+func precedenceOf(expression ast.Expression) int {
+	switch expression := expression.(type) {
+	case *ast.InfixExpression:
+		return parser.Precedence(token.Type(expression.Operator))
+	case *ast.PrefixExpression:
+		return parser.PREFIX
+	case *ast.CallExpression, *ast.IndexExpression, *ast.MemberExpression:
+		return parser.CALL
+	case *ast.IntegerLiteral:
+		if expression.Value < 0 {
+			return parser.PREFIX
+		}
+	case *ast.FloatLiteral:
+		if expression.Value < 0 {
+			return parser.PREFIX
+		}
+	}
+	return parser.INDEX + 1
+}
+
+func (printer *printer) block(block *ast.BlockStatement) {
+	end := -1
+	if printer.source != "" {
+		if closer, ok := printer.closers[block.Token.Pos.Offset]; ok {
+			end = closer
+		}
+	}
+
+	hasComments := end >= 0 && printer.hasCommentBefore(end)
+	if len(block.Statements) == 0 && !hasComments {
+		printer.write("{}")
+		return
+	}
+
+	printer.write("{")
+	printer.indent += 1
+	printer.newline()
+	printer.statements(block.Statements, end)
+	printer.indent -= 1
+	printer.newline()
+	printer.write("}")
+}
+
+func (printer *printer) expression(expression ast.Expression, precedence int) {
+	if precedenceOf(expression) < precedence {
+		printer.write("(")
+		printer.expression(expression, parser.LOWEST)
+		printer.write(")")
+		return
+	}
+
+	switch expression := expression.(type) {
+	case *ast.Identifier:
+		printer.write(expression.Value)
+	case *ast.IntegerLiteral:
+		if expression.Token.Literal != "" && expression.Value >= 0 {
+			printer.write(expression.Token.Literal)
+		} else {
+			printer.write(strconv.FormatInt(expression.Value, 10))
+		}
+	case *ast.FloatLiteral:
+		printer.write(formatFloat(expression))
+	case *ast.Boolean:
+		printer.write(strconv.FormatBool(expression.Value))
+	case *ast.StringLiteral:
+		printer.write(`"` + expression.Value + `"`)
+	case *ast.PrefixExpression:
+		printer.write(expression.Operator)
+		printer.expression(expression.Right, parser.PREFIX)
+	case *ast.InfixExpression:
+		precedence := precedenceOf(expression)
+		printer.expression(expression.Left, precedence)
+		printer.write(" " + expression.Operator + " ")
+		printer.operand(expression.Right, precedence+1)
+	case *ast.IfExpression:
+		printer.write("if (")
+		printer.expression(expression.Condition, parser.LOWEST)
+		printer.write(") ")
+		printer.block(expression.Consequence)
+		if expression.Alternative != nil {
+			if printer.commentsBeforeElse(expression.Alternative) {
+				printer.newline()
+				printer.write("else ")
+			} else {
+				printer.write(" else ")
+			}
+			printer.block(expression.Alternative)
+		}
+	case *ast.FunctionLiteral:
+		printer.function("fn", expression.Parameters, expression.Body)
+	case *ast.MacroLiteral:
+		printer.function("macro", expression.Parameters, expression.Body)
+	case *ast.CallExpression:
+		printer.expression(expression.Function, parser.CALL)
+		printer.list("(", ")", false, expression.Token, startsOf(expression.Arguments), lastOf(expression.Arguments), func(i int) {
+			printer.expression(expression.Arguments[i], parser.LOWEST)
+		})
+	case *ast.IndexExpression:
+		printer.expression(expression.Left, parser.CALL)
+		printer.write("[")
+		printer.expression(expression.Index, parser.LOWEST)
+		printer.write("]")
+	case *ast.MemberExpression:
+		printer.expression(expression.Left, parser.CALL)
+		printer.write("." + expression.Member.Value)
+	case *ast.ImportExpression:
+		printer.write("import(")
+		printer.expression(expression.Path, parser.LOWEST)
+		printer.write(")")
+	case *ast.ArrayLiteral:
+		printer.list("[", "]", false, expression.Token, startsOf(expression.Elements), lastOf(expression.Elements), func(i int) {
+			printer.expression(expression.Elements[i], parser.LOWEST)
+		})
+	case *ast.HashLiteral:
+		keys := ast.SortedKeys(expression)
+		values := []ast.Expression{}
+		for _, key := range keys {
+			values = append(values, expression.Pairs[key])
+		}
+		printer.list("{", "}", true, expression.Token, startsOf(keys), lastOf(values), func(i int) {
+			printer.expression(keys[i], parser.LOWEST)
+			printer.write(": ")
+			printer.operand(expression.Pairs[keys[i]], parser.LOWEST)
+		})
+	}
+}
+
+// operand prints an expression following an operator. Comments between
+// the operator and the expression trail the operator, and the expression
+// moves onto an indented line of its own.
+func (printer *printer) operand(expression ast.Expression, precedence int) {
+	start := startOf(expression)
+	if !printer.hasCommentBefore(start) {
+		printer.expression(expression, precedence)
+		return
+	}
+
+	printer.indent += 1
+	for printer.hasCommentBefore(start) {
+		printer.write(printer.comments[printer.next].Literal)
+		printer.next += 1
+		printer.newline()
+	}
+	printer.expression(expression, precedence)
+	printer.indent -= 1
+}
+
+// commentsBeforeElse prints the comments between the consequence of an
+// if expression and the else keyword preceding alternative, and reports
+// whether there were any. A comment on the line of the closing brace of
+// the consequence trails it, others go on lines of their own.
+func (printer *printer) commentsBeforeElse(alternative *ast.BlockStatement) bool {
+	keyword, ok := printer.lastTokenBefore(alternative.Token.Pos.Offset)
+	if !ok || !printer.hasCommentBefore(keyword.Pos.Offset) {
+		return false
+	}
+
+	closer, _ := printer.lastTokenBefore(keyword.Pos.Offset)
+	for printer.hasCommentBefore(keyword.Pos.Offset) {
+		comment := printer.comments[printer.next]
+		if comment.Pos.Line == closer.Pos.Line {
+			printer.write(" ")
+		} else {
+			printer.newline()
+		}
+		printer.write(comment.Literal)
+		printer.next += 1
+	}
+	return true
+}
+
+// startOf returns the source offset of the first token of expression.
+func startOf(expression ast.Expression) int {
+	switch expression := expression.(type) {
+	case *ast.InfixExpression:
+		return startOf(expression.Left)
+	case *ast.CallExpression:
+		return startOf(expression.Function)
+	case *ast.IndexExpression:
+		return startOf(expression.Left)
+	case *ast.MemberExpression:
+		return startOf(expression.Left)
+	default:
+		return ast.Pos(expression).Offset
+	}
+}
+
+func startsOf(expressions []ast.Expression) []int {
+	starts := make([]int, len(expressions))
+	for i, expression := range expressions {
+		starts[i] = startOf(expression)
+	}
+	return starts
+}
+
+func (printer *printer) function(keyword string, parameters []*ast.Identifier, body *ast.BlockStatement) {
+	names := []string{}
+	for _, parameter := range parameters {
+		names = append(names, parameter.Value)
+	}
+
+	printer.write(keyword + "(" + strings.Join(names, ", ") + ") ")
+	printer.block(body)
+}
+
+// list prints comma separated items between open and close, starting in
+// the source at starts. The items go on a single line if that line fits
+// into maxWidth, otherwise on one line each. Only a last item ending in a
+// block, like the function in map(xs, fn(x) { ... }), may span several
+// lines without breaking the list. Hash literals allow, and get, a
+// trailing comma when broken. Comments between the items break the list
+// and stay next to the items they precede or trail, and single blank
+// lines between its items are then preserved.
+func (printer *printer) list(open, close string, trailingComma bool, opener token.Token, starts []int, last ast.Expression, item func(i int)) {
+	n := len(starts)
+	end, hasComments := printer.listComments(opener, starts)
+
+	if !hasComments {
+		mark, next := printer.out.Len(), printer.next
+		lastMark := mark
+
+		printer.write(open)
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				printer.write(", ")
+			}
+			lastMark = printer.out.Len()
+			item(i)
+		}
+		printer.write(close)
+
+		if n == 0 || printer.fits(mark, lastMark, endsWithBlock(last)) {
+			return
+		}
+
+		printer.out.Truncate(mark)
+		printer.next = next
+	}
+
+	printer.write(open)
+	printer.indent += 1
+	list := &items{started: true, previousEnd: -1}
+	for i := 0; i < n; i++ {
+		printer.commentsBefore(starts[i], list)
+		printer.item(starts[i], list)
+		item(i)
+		if i < n-1 || trailingComma {
+			printer.write(",")
+		}
+		list.previousEnd = -1
+		if !hasComments {
+			continue
+		}
+
+		bound := end
+		if i+1 < n {
+			bound = starts[i+1]
+		}
+		if last, ok := printer.lastTokenBefore(bound); ok {
+			list.previousEnd = last.Pos.Offset
+			if comment, ok := printer.trailingComment(last, bound); ok {
+				list.previousEnd = comment.Pos.Offset
+			}
+		}
+	}
+	if hasComments {
+		printer.commentsBefore(end, list)
+	}
+	printer.indent -= 1
+	printer.newline()
+	printer.write(close)
+}
+
+// listComments returns the offset of the bracket closing a list opened by
+// opener and reports whether a comment lies between the items starting at
+// starts rather than inside one of them.
+func (printer *printer) listComments(opener token.Token, starts []int) (int, bool) {
+	end, ok := printer.closers[opener.Pos.Offset]
+	if printer.source == "" || !ok {
+		return -1, false
+	}
+
+	for _, comment := range printer.comments[printer.next:] {
+		offset := comment.Pos.Offset
+		if offset >= end {
+			break
+		}
+
+		between := true
+		for i, start := range starts {
+			bound := end
+			if i+1 < len(starts) {
+				bound = starts[i+1]
+			}
+			itemEnd, _ := printer.lastTokenBefore(bound)
+			if itemEnd.Type == token.COMMA {
+				itemEnd, _ = printer.lastTokenBefore(itemEnd.Pos.Offset)
+			}
+			if start <= offset && offset < itemEnd.Pos.Offset {
+				between = false
+			}
+		}
+		if between {
+			return end, true
+		}
+	}
+
+	return end, false
+}
+
+// fits reports whether the output written since mark starts with a line
+// within maxWidth and, unless hanging allows the last item starting at
+// lastMark to break, has no further lines.
+func (printer *printer) fits(mark, lastMark int, hanging bool) bool {
+	written := printer.out.Bytes()
+	lineStart := bytes.LastIndexByte(written[:mark], '\n') + 1
+
+	lineEnd := bytes.IndexByte(written[mark:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(written)
+	} else {
+		lineEnd += mark
+		if !hanging || lineEnd < lastMark {
+			return false
+		}
+	}
+
+	return lineEnd-lineStart <= maxWidth
+}
+
+func lastOf(expressions []ast.Expression) ast.Expression {
+	if len(expressions) == 0 {
+		return nil
+	}
+	return expressions[len(expressions)-1]
+}
+
+// endsWithBlock reports whether the printed form of expression ends with
+// the closing brace of a block.
+func endsWithBlock(expression ast.Expression) bool {
+	switch expression := expression.(type) {
+	case *ast.FunctionLiteral, *ast.MacroLiteral, *ast.IfExpression:
+		return true
+	case *ast.CallExpression:
+		return endsWithBlock(lastOf(expression.Arguments))
+	default:
+		return false
+	}
+}
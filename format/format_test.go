@@ -0,0 +1,235 @@
+package format
+
+import (
+	goast "go/ast"
+	goparser "go/parser"
+	"go/token"
+	"monkey/lexer"
+	"monkey/parser"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x=5", "let x = 5;\n"},
+		{"a + (b * c)", "a + b * c\n"},
+		{"(a + b) * c", "(a + b) * c\n"},
+		{"a - (b - c)", "a - (b - c)\n"},
+		{"(a - b) - c", "a - b - c\n"},
+		{"-(a + b)", "-(a + b)\n"},
+		{"(-a)[0]", "(-a)[0]\n"},
+		{"-(a[0])", "-a[0]\n"},
+		{"(f(1))[0]", "f(1)[0]\n"},
+		{"((1 < 2) == true)", "1 < 2 == true\n"},
+		{"3 + 4; -5 * 5", "3 + 4;\n-5 * 5\n"},
+		{"a; (b)", "a\nb\n"},
+		{"a; (b + c) * d", "a;\n(b + c) * d\n"},
+		{"a; [b]", "a;\n[b]\n"},
+		{"let r = 2.50 * -1e3", "let r = 2.50 * -1e3;\n"},
+		{"(math.sqrt)(2) + (-x).y + 1.z", "math.sqrt(2) + (-x).y + 1.z\n"},
+		{`import "util.mk"   as u;let v = import ( "v.mk" )`, "import \"util.mk\" as u;\nlet v = import(\"v.mk\");\n"},
+		{"puts(1); puts(2);", "puts(1)\nputs(2)\n"},
+		{
+			"let add = fn(a,b){return a+b;};",
+			"let add = fn(a, b) {\n    return a + b;\n};\n",
+		},
+		{
+			"if (x < y) { x } else { y }",
+			"if (x < y) {\n    x\n} else {\n    y\n}\n",
+		},
+		{"fn() {}", "fn() {}\n"},
+		{`{"b": 2, "a": 1}`, "{\"b\": 2, \"a\": 1}\n"},
+		{
+			"let a = 1;\n\n\n\nlet b = 2;\nlet c = 3;",
+			"let a = 1;\n\nlet b = 2;\nlet c = 3;\n",
+		},
+		{
+			"// leading\nlet x = 1; // trailing\n\n// before y\nlet y = fn() {\n// inside\nx\n// end of block\n};\n// end of file",
+			"// leading\nlet x = 1; // trailing\n\n// before y\nlet y = fn() {\n    // inside\n    x\n    // end of block\n};\n// end of file\n",
+		},
+		{
+			"let f = fn() { // on the brace\n  1 };",
+			"let f = fn() {\n    // on the brace\n    1\n};\n",
+		},
+		{
+			"let xs = [1111111111, 2222222222, 3333333333, 4444444444, 5555555555, 6666666666, 7777777777];",
+			"let xs = [\n    1111111111,\n    2222222222,\n    3333333333,\n    4444444444,\n    5555555555,\n    6666666666,\n    7777777777\n];\n",
+		},
+		{
+			`let config = {"name": "monkey", "description": "a programming language", "version": 1};`,
+			"let config = {\n    \"name\": \"monkey\",\n    \"description\": \"a programming language\",\n    \"version\": 1,\n};\n",
+		},
+		{
+			"map([1, 2], fn(x) { x * 2 })",
+			"map([1, 2], fn(x) {\n    x * 2\n})\n",
+		},
+		{"[1, // one\n 2]", "[\n    1, // one\n    2\n]\n"},
+		{"f(\n// first\na,\n\nb // last\n)", "f(\n    // first\n    a,\n\n    b // last\n)\n"},
+		{"{\"a\": // a\n 1}", "{\n    \"a\": // a\n        1,\n}\n"},
+		{"let x = // c\n 5;", "let x = // c\n    5;\n"},
+		{"1 + // c\n 2", "1 + // c\n    2\n"},
+		{
+			"if (a) { b } // c\n else { d }",
+			"if (a) {\n    b\n} // c\nelse {\n    d\n}\n",
+		},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		formatted, err := Source([]byte(test.input))
+		if err != nil {
+			t.Errorf("Source(%q) returned error: %s", test.input, err)
+			continue
+		}
+
+		if string(formatted) != test.expected {
+			t.Errorf("Source(%q) wrong.\nexpected=%q\ngot=     %q", test.input, test.expected, formatted)
+		}
+	}
+}
+
+func TestSourceParseError(t *testing.T) {
+	_, err := Source([]byte("let = 5;"))
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+
+	if !strings.HasPrefix(err.Error(), "expected next token to be IDENT, got = instead") {
+		t.Errorf("wrong error message. got=%q", err.Error())
+	}
+}
+
+// programs are larger inputs for TestIdempotency, besides those taken
+// from the parser and evaluator tests.
+var programs = []string{
+	`// Computes Fibonacci numbers.
+let fibonacci = fn(x) {
+	if (x == 0) { 0 } else {
+		if (x == 1) { return 1; } // base case
+		fibonacci(x - 1) + fibonacci(x - 2);
+	}
+};
+
+let map = fn(arr, f) { let iter = fn(arr, accumulated) { if (len(arr) == 0) { accumulated } else { iter(rest(arr), push(accumulated, f(first(arr)))); } }; iter(arr, []); };
+let people = [{"name": "Alice", "age": 24, "languages": ["Go", "Monkey"]}, {"name": "Anna", "age": 28}];
+puts(map(people, fn(person) { person["name"] }));
+let unless = macro(condition, consequence, alternative) {
+	quote(if (!(unquote(condition))) { unquote(consequence); } else { unquote(alternative); });
+};
+// trailing comment`,
+	`let xs = [
+	// first
+	1, // one
+	2 + // two
+	3,
+
+	// last
+	f(a, // a
+	  b) // b
+];
+let h = {"a": // a
+	1, "b": 2 // b
+};
+if (x) { y } // after y
+// before else
+else { z }`,
+}
+
+// corpus returns the programs and the string literals of the parser and
+// evaluator tests that parse as Monkey programs.
+func corpus(t *testing.T) []string {
+	files, err := filepath.Glob("../parser/*_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	evaluatorFiles, err := filepath.Glob("../evaluator/*_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files = append(files, evaluatorFiles...)
+
+	inputs := append([]string{}, programs...)
+	for _, file := range files {
+		tree, err := goparser.ParseFile(token.NewFileSet(), file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		goast.Inspect(tree, func(node goast.Node) bool {
+			literal, ok := node.(*goast.BasicLit)
+			if !ok || literal.Kind != token.STRING {
+				return true
+			}
+
+			input, err := strconv.Unquote(literal.Value)
+			if err != nil || strings.TrimSpace(input) == "" {
+				return true
+			}
+
+			p := parser.New(lexer.New(input))
+			p.ParseProgram()
+			if len(p.Errors()) == 0 {
+				inputs = append(inputs, input)
+			}
+			return true
+		})
+	}
+
+	if len(inputs) < 100 {
+		t.Fatalf("too few test inputs found: %d", len(inputs))
+	}
+	return inputs
+}
+
+func TestIdempotency(t *testing.T) {
+	for _, input := range corpus(t) {
+		once, err := Source([]byte(input))
+		if err != nil {
+			t.Errorf("Source(%q) returned error: %s", input, err)
+			continue
+		}
+
+		twice, err := Source(once)
+		if err != nil {
+			t.Errorf("Source(%q) returned error: %s", once, err)
+			continue
+		}
+
+		if string(once) != string(twice) {
+			t.Errorf("formatting is not idempotent.\nonce= %q\ntwice=%q", once, twice)
+		}
+
+		if parse(t, input) != parse(t, string(once)) {
+			t.Errorf("formatting changed the meaning of %q.\ngot=%q", input, once)
+		}
+
+		if strings.Count(input, "//") != strings.Count(string(once), "//") {
+			t.Errorf("formatting lost comments of %q.\ngot=%q", input, once)
+		}
+	}
+}
+
+func parse(t *testing.T, input string) string {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors for %q: %v", input, p.Errors())
+	}
+	return program.String()
+}
+
+func TestNode(t *testing.T) {
+	p := parser.New(lexer.New("let f = fn(x) { (x + 1) * 2 }; f(3)"))
+	program := p.ParseProgram()
+
+	expected := "let f = fn(x) {\n    (x + 1) * 2\n};\nf(3)"
+	if Node(program) != expected {
+		t.Errorf("Node wrong.\nexpected=%q\ngot=     %q", expected, Node(program))
+	}
+}
@@ -2,6 +2,7 @@ package lexer
 
 import (
 	"monkey/token"
+	"strings"
 )
 
 type Lexer struct {
@@ -9,18 +10,27 @@ type Lexer struct {
 	position     int // Current position in input (points to the current char)
 	readPosition int // Current reading position in input (points to after the current char)
 	char         byte
+	line         int // Line of the current char, starting at 1
+	lineStart    int // Position of the first char on the current line
+
+	comments []token.Token
 }
 
 func New(input string) *Lexer {
-	lexer := &Lexer{input: input}
+	lexer := &Lexer{input: input, line: 1}
 	lexer.readChar()
+	if lexer.char == '#' && lexer.peekChar() == '!' {
+		lexer.comments = append(lexer.comments, lexer.readComment())
+	}
 	return lexer
 }
 
 func (lexer *Lexer) NextToken() token.Token {
 	var tok token.Token
 
-	lexer.skipWhitespace()
+	lexer.skipWhitespaceAndComments()
+
+	pos := lexer.currPosition()
 
 	switch lexer.char {
 	case '=':
@@ -42,6 +52,8 @@ func (lexer *Lexer) NextToken() token.Token {
 		tok = newToken(token.RPAREN, lexer.char)
 	case ',':
 		tok = newToken(token.COMMA, lexer.char)
+	case '.':
+		tok = newToken(token.DOT, lexer.char)
 	case '+':
 		tok = newToken(token.PLUS, lexer.char)
 	case '-':
@@ -81,10 +93,11 @@ func (lexer *Lexer) NextToken() token.Token {
 		if isLetter(lexer.char) {
 			tok.Literal = lexer.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
+			tok.Pos = pos
 			return tok
 		} else if isDigit(lexer.char) {
-			tok.Type = token.INT
-			tok.Literal = lexer.readNumber()
+			tok.Literal, tok.Type = lexer.readNumber()
+			tok.Pos = pos
 			return tok
 		} else {
 			tok = newToken(token.ILLEGAL, lexer.char)
@@ -92,10 +105,15 @@ func (lexer *Lexer) NextToken() token.Token {
 	}
 	lexer.readChar()
 
+	tok.Pos = pos
 	return tok
 }
 
 func (lexer *Lexer) readChar() {
+	if lexer.char == '\n' {
+		lexer.line += 1
+		lexer.lineStart = lexer.readPosition
+	}
 	if lexer.readPosition >= len(lexer.input) {
 		lexer.char = 0 // NULL character
 	} else {
@@ -105,6 +123,14 @@ func (lexer *Lexer) readChar() {
 	lexer.readPosition += 1
 }
 
+func (lexer *Lexer) currPosition() token.Position {
+	return token.Position{
+		Offset: lexer.position,
+		Line:   lexer.line,
+		Column: lexer.position - lexer.lineStart + 1,
+	}
+}
+
 func (lexer *Lexer) readString() string {
 	position := lexer.position + 1
 	for {
@@ -124,18 +150,68 @@ func (lexer *Lexer) readIdentifier() string {
 	return lexer.input[position:lexer.position]
 }
 
+// Comments returns the line comments skipped so far, in source order.
+// A shebang line at the very start of the input counts as a comment.
+func (lexer *Lexer) Comments() []token.Token {
+	return lexer.comments
+}
+
+func (lexer *Lexer) skipWhitespaceAndComments() {
+	lexer.skipWhitespace()
+	for lexer.char == '/' && lexer.peekChar() == '/' {
+		lexer.comments = append(lexer.comments, lexer.readComment())
+		lexer.skipWhitespace()
+	}
+}
+
+func (lexer *Lexer) readComment() token.Token {
+	pos := lexer.currPosition()
+	for lexer.char != '\n' && lexer.char != 0 {
+		lexer.readChar()
+	}
+	literal := strings.TrimRight(lexer.input[pos.Offset:lexer.position], " \t\r")
+	return token.Token{Type: token.COMMENT, Literal: literal, Pos: pos}
+}
+
 func (lexer *Lexer) skipWhitespace() {
 	for isWhitespace(lexer.char) {
 		lexer.readChar()
 	}
 }
 
-func (lexer *Lexer) readNumber() string {
+// readNumber reads an integer, or a float if the digits are followed by a
+// fraction or an exponent.
+func (lexer *Lexer) readNumber() (string, token.Type) {
 	position := lexer.position
+	tokenType := token.Type(token.INT)
+
+	lexer.readDigits()
+	if lexer.char == '.' && isDigit(lexer.peekChar()) {
+		tokenType = token.FLOAT
+		lexer.readChar()
+		lexer.readDigits()
+	}
+	if lexer.char == 'e' || lexer.char == 'E' {
+		exponent := lexer.readPosition
+		if exponent < len(lexer.input) && (lexer.input[exponent] == '+' || lexer.input[exponent] == '-') {
+			exponent += 1
+		}
+		if exponent < len(lexer.input) && isDigit(lexer.input[exponent]) {
+			tokenType = token.FLOAT
+			for lexer.position < exponent {
+				lexer.readChar()
+			}
+			lexer.readDigits()
+		}
+	}
+
+	return lexer.input[position:lexer.position], tokenType
+}
+
+func (lexer *Lexer) readDigits() {
 	for isDigit(lexer.char) {
 		lexer.readChar()
 	}
-	return lexer.input[position:lexer.position]
 }
 
 func (lexer *Lexer) peekChar() byte {
@@ -155,3 +155,128 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{"5", token.INT, "5"},
+		{"3.14", token.FLOAT, "3.14"},
+		{"1e9", token.FLOAT, "1e9"},
+		{"2.5E-3", token.FLOAT, "2.5E-3"},
+		{"6e+2", token.FLOAT, "6e+2"},
+		{"7.", token.INT, "7"},
+		{"8e", token.INT, "8"},
+		{"9e-", token.INT, "9"},
+	}
+
+	for _, test := range tests {
+		tok := New(test.input).NextToken()
+
+		if tok.Type != test.expectedType || tok.Literal != test.expectedLiteral {
+			t.Errorf("wrong token for %q. expected=%s %q, got=%s %q",
+				test.input, test.expectedType, test.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestTokenPositions(t *testing.T) {
+	input := "let x = 5;\n  x + \"ab\";\n"
+
+	expectedPositions := []token.Position{
+		{Offset: 0, Line: 1, Column: 1},
+		{Offset: 4, Line: 1, Column: 5},
+		{Offset: 6, Line: 1, Column: 7},
+		{Offset: 8, Line: 1, Column: 9},
+		{Offset: 9, Line: 1, Column: 10},
+		{Offset: 13, Line: 2, Column: 3},
+		{Offset: 15, Line: 2, Column: 5},
+		{Offset: 17, Line: 2, Column: 7},
+		{Offset: 21, Line: 2, Column: 11},
+		{Offset: 23, Line: 3, Column: 1},
+	}
+
+	lexer := New(input)
+
+	for i, expected := range expectedPositions {
+		tok := lexer.NextToken()
+
+		if tok.Pos != expected {
+			t.Fatalf("tests[%d] - position wrong for %q. expected=%+v, got=%+v",
+				i, tok.Literal, expected, tok.Pos)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// leading
+let x = 5; // trailing
+x / 2 //
+`
+
+	expectedTokens := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for i, expected := range expectedTokens {
+		tok := lexer.NextToken()
+
+		if tok.Type != expected.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, expected.expectedType, tok.Type)
+		}
+
+		if tok.Literal != expected.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, expected.expectedLiteral, tok.Literal)
+		}
+	}
+
+	expectedComments := []token.Token{
+		{Type: token.COMMENT, Literal: "// leading", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+		{Type: token.COMMENT, Literal: "// trailing", Pos: token.Position{Offset: 22, Line: 2, Column: 12}},
+		{Type: token.COMMENT, Literal: "//", Pos: token.Position{Offset: 40, Line: 3, Column: 7}},
+	}
+
+	comments := lexer.Comments()
+	if len(comments) != len(expectedComments) {
+		t.Fatalf("wrong number of comments. expected=%d, got=%d", len(expectedComments), len(comments))
+	}
+
+	for i, expected := range expectedComments {
+		if comments[i] != expected {
+			t.Errorf("comments[%d] wrong. expected=%+v, got=%+v", i, expected, comments[i])
+		}
+	}
+}
+
+func TestShebang(t *testing.T) {
+	input := "#!/usr/bin/env monkey run\nputs(1)"
+
+	lexer := New(input)
+
+	tok := lexer.NextToken()
+	if tok.Type != token.IDENT || tok.Literal != "puts" {
+		t.Fatalf("shebang line not skipped. got=%+v", tok)
+	}
+
+	comments := lexer.Comments()
+	if len(comments) != 1 || comments[0].Literal != "#!/usr/bin/env monkey run" {
+		t.Errorf("shebang line not recorded as comment. got=%+v", comments)
+	}
+}
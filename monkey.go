@@ -0,0 +1,190 @@
+// Package monkey embeds the Monkey interpreter in Go programs.
+//
+//	interpreter := monkey.New(monkey.WithGlobal("name", &object.String{Value: "world"}))
+//	result, err := interpreter.Run(ctx, `"hello " + name`)
+package monkey
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"strings"
+
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+)
+
+// Interpreter runs Monkey programs. Bindings and macros defined by one call
+// to Run are visible to the next.
+type Interpreter struct {
+	runtime  *evaluator.Runtime
+	env      *object.Environment
+	macroEnv *object.Environment
+}
+
+// Option configures an Interpreter.
+type Option func(interpreter *Interpreter)
+
+// WithGlobal binds name to value before the first program runs.
+func WithGlobal(name string, value object.Object) Option {
+	return func(interpreter *Interpreter) {
+		interpreter.env.Set(name, value)
+	}
+}
+
+// WithLimits bounds the resources each call to Run may use.
+func WithLimits(limits evaluator.Limits) Option {
+	return func(interpreter *Interpreter) {
+		interpreter.runtime.Limits = limits
+	}
+}
+
+// WithStdout sets the writer puts and print write to. It defaults to
+// os.Stdout.
+func WithStdout(w io.Writer) Option {
+	return func(interpreter *Interpreter) {
+		interpreter.runtime.Stdout = w
+	}
+}
+
+// WithStderr sets the writer eprint writes to. It defaults to os.Stderr.
+func WithStderr(w io.Writer) Option {
+	return func(interpreter *Interpreter) {
+		interpreter.runtime.Stderr = w
+	}
+}
+
+// WithStdin sets the reader readLine and readAll read from. It defaults
+// to os.Stdin.
+func WithStdin(r io.Reader) Option {
+	return func(interpreter *Interpreter) {
+		interpreter.runtime.Stdin = r
+	}
+}
+
+// WithSeed seeds the random builtins, so that runs are reproducible. By
+// default they are seeded with the current time.
+func WithSeed(seed int64) Option {
+	return func(interpreter *Interpreter) {
+		interpreter.runtime.Rand = rand.New(rand.NewSource(seed))
+	}
+}
+
+// WithClock sets the clock of the time builtins, such as a fake clock in
+// tests. It defaults to evaluator.SystemClock.
+func WithClock(clock object.Clock) Option {
+	return func(interpreter *Interpreter) {
+		interpreter.runtime.Clock = clock
+	}
+}
+
+// WithFS gives the file builtins access to fsys, such as a directory from
+// evaluator.DirFS, which may be wrapped by evaluator.ReadOnlyFS. Without
+// this option scripts cannot access files.
+func WithFS(fsys object.FileSystem) Option {
+	return func(interpreter *Interpreter) {
+		interpreter.runtime.FS = fsys
+	}
+}
+
+// WithImportDir sets the directory of the file system given with WithFS
+// that imports are relative to, such as the directory of the script being
+// run. It defaults to the root of the file system.
+func WithImportDir(dir string) Option {
+	return func(interpreter *Interpreter) {
+		interpreter.runtime.ImportDir = dir
+	}
+}
+
+// New returns an Interpreter with an empty global environment.
+func New(options ...Option) *Interpreter {
+	interpreter := &Interpreter{
+		runtime:  evaluator.NewRuntime(),
+		env:      object.NewEnvironment(),
+		macroEnv: object.NewEnvironment(),
+	}
+	for _, option := range options {
+		option(interpreter)
+	}
+	return interpreter
+}
+
+// Run parses, expands and evaluates source and returns the value of its
+// last statement, or NULL if that statement produces no value. It returns
+// a *ParseError if source does not parse and a *RuntimeError if evaluation
+// fails. Evaluation stops when ctx is done or a limit is exceeded, with a
+// *RuntimeError that wraps ctx.Err() or one of the limit errors of the
+// evaluator package.
+func (interpreter *Interpreter) Run(ctx context.Context, source string) (object.Object, error) {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, &ParseError{Errors: p.Errors()}
+	}
+
+	evaluator.DefineMacros(program, interpreter.macroEnv)
+	expanded, err := interpreter.runtime.ExpandMacros(ctx, program, interpreter.macroEnv)
+	if err != nil {
+		return nil, &RuntimeError{Message: err.Message, Err: err.Err}
+	}
+
+	evaluated := interpreter.runtime.Eval(ctx, expanded, interpreter.env)
+	if evaluated == nil {
+		return evaluator.NULL, nil
+	}
+	if err, ok := evaluated.(*object.Error); ok {
+		return nil, &RuntimeError{Message: err.Message, Err: err.Err}
+	}
+
+	return evaluated, nil
+}
+
+// Set binds name to value in the global environment.
+func (interpreter *Interpreter) Set(name string, value object.Object) {
+	interpreter.env.Set(name, value)
+}
+
+// Get returns the value bound to name in the global environment.
+func (interpreter *Interpreter) Get(name string) (object.Object, bool) {
+	return interpreter.env.Get(name)
+}
+
+// Register binds name to a builtin that calls the Go function fn. Monkey
+// arguments and results are converted to and from Go values as described
+// by evaluator.NewBuiltin.
+func (interpreter *Interpreter) Register(name string, fn interface{}) error {
+	builtin, err := evaluator.NewBuiltin(name, fn)
+	if err != nil {
+		return err
+	}
+
+	interpreter.env.Set(name, builtin)
+	return nil
+}
+
+// ParseError is returned by Run when the source has syntax errors.
+type ParseError struct {
+	Errors []string
+}
+
+func (err *ParseError) Error() string {
+	return strings.Join(err.Errors, "\n")
+}
+
+// RuntimeError is returned by Run when evaluation produces an error value.
+// Err is the Go error behind it, such as context.DeadlineExceeded when
+// evaluation timed out.
+type RuntimeError struct {
+	Message string
+	Err     error
+}
+
+func (err *RuntimeError) Error() string {
+	return err.Message
+}
+
+func (err *RuntimeError) Unwrap() error {
+	return err.Err
+}
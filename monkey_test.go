@@ -0,0 +1,301 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"monkey/evaluator"
+	"monkey/object"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 + 2", "3"},
+		{`"hello " + name`, "hello monkey"},
+		{"let x = 5;", "null"},
+		{"let double = fn(x) { x * 2 }; double(21)", "42"},
+		{"let unless = macro(c, t) { quote(if (!(unquote(c))) { unquote(t) }) }; unless(false, 7)", "7"},
+	}
+
+	for _, test := range tests {
+		interpreter := New(WithGlobal("name", &object.String{Value: "monkey"}))
+
+		result, err := interpreter.Run(context.Background(), test.input)
+		if err != nil {
+			t.Errorf("Run(%q) returned error: %s", test.input, err)
+			continue
+		}
+		if result.Inspect() != test.expected {
+			t.Errorf("Run(%q) wrong. expected=%q, got=%q", test.input, test.expected, result.Inspect())
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	interpreter := New()
+
+	_, err := interpreter.Run(context.Background(), "let = 5;")
+	var parseError *ParseError
+	if !errors.As(err, &parseError) {
+		t.Fatalf("expected *ParseError, got=%T (%v)", err, err)
+	}
+	if parseError.Errors[0] != "expected next token to be IDENT, got = instead" {
+		t.Errorf("wrong parse error. got=%q", parseError.Errors[0])
+	}
+
+	_, err = interpreter.Run(context.Background(), "1 + true")
+	var runtimeError *RuntimeError
+	if !errors.As(err, &runtimeError) {
+		t.Fatalf("expected *RuntimeError, got=%T (%v)", err, err)
+	}
+	if runtimeError.Error() != "type mismatch: INTEGER + BOOLEAN" {
+		t.Errorf("wrong runtime error. got=%q", runtimeError.Error())
+	}
+
+	_, err = interpreter.Run(context.Background(), "let m = macro() { 1 }; m();")
+	if !errors.As(err, &runtimeError) {
+		t.Fatalf("expected *RuntimeError, got=%T (%v)", err, err)
+	}
+	if runtimeError.Error() != "macro `m` must return a QUOTE, got INTEGER" {
+		t.Errorf("wrong macro error. got=%q", runtimeError.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := interpreter.Run(ctx, "1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got=%v", err)
+	}
+}
+
+func TestGlobals(t *testing.T) {
+	interpreter := New()
+	interpreter.Set("x", &object.Integer{Value: 40})
+
+	if _, err := interpreter.Run(context.Background(), "let y = x + 2;"); err != nil {
+		t.Fatalf("Run returned error: %s", err)
+	}
+
+	y, ok := interpreter.Get("y")
+	if !ok {
+		t.Fatalf("y is not bound")
+	}
+	if y.Inspect() != "42" {
+		t.Errorf("y wrong. expected=%q, got=%q", "42", y.Inspect())
+	}
+
+	if _, ok := interpreter.Get("z"); ok {
+		t.Errorf("z should not be bound")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	interpreter := New()
+
+	err := interpreter.Register("lookup", func(users map[string]int, name string) (int, error) {
+		age, ok := users[name]
+		if !ok {
+			return 0, errors.New("no such user: " + name)
+		}
+		return age, nil
+	})
+	if err != nil {
+		t.Fatalf("Register returned error: %s", err)
+	}
+
+	result, err := interpreter.Run(context.Background(), `lookup({"ann": 31}, "ann")`)
+	if err != nil {
+		t.Fatalf("Run returned error: %s", err)
+	}
+	if result.Inspect() != "31" {
+		t.Errorf("wrong result. expected=%q, got=%q", "31", result.Inspect())
+	}
+
+	_, err = interpreter.Run(context.Background(), `lookup({"ann": 31}, "bob")`)
+	if err == nil || err.Error() != "no such user: bob" {
+		t.Errorf("wrong error. got=%v", err)
+	}
+
+	if err := interpreter.Register("bad", 42); err == nil {
+		t.Errorf("expected error registering a non-function")
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	interpreter := New()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := interpreter.Run(ctx, `
+let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } };
+fib(100)
+`)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got=%v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("evaluation took %s after the deadline", elapsed)
+	}
+
+	result, err := interpreter.Run(context.Background(), "fib(10)")
+	if err != nil {
+		t.Fatalf("Run returned error after timeout: %s", err)
+	}
+	if result.Inspect() != "55" {
+		t.Errorf("wrong result. expected=%q, got=%q", "55", result.Inspect())
+	}
+}
+
+func TestRunMacroTimeout(t *testing.T) {
+	interpreter := New()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := interpreter.Run(ctx, `
+let forever = macro() {
+  let loop = fn(n) { loop(n + 1) };
+  loop(0);
+  quote(1)
+};
+forever()
+`)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got=%v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("macro expansion took %s after the deadline", elapsed)
+	}
+}
+
+func TestWithLimits(t *testing.T) {
+	interpreter := New(WithLimits(evaluator.Limits{MaxSteps: 1000}))
+
+	_, err := interpreter.Run(context.Background(), "let loop = fn(n) { loop(n + 1) }; loop(0)")
+	if !errors.Is(err, evaluator.ErrStepLimit) {
+		t.Fatalf("expected ErrStepLimit, got=%v", err)
+	}
+
+	if _, err := interpreter.Run(context.Background(), "loop"); err != nil {
+		t.Errorf("each run should get a new step budget, got=%v", err)
+	}
+}
+
+func TestWithLimitsInMacros(t *testing.T) {
+	tests := []struct {
+		limits   evaluator.Limits
+		body     string
+		expected error
+	}{
+		{evaluator.Limits{MaxSteps: 1000}, "let loop = fn(n) { loop(n + 1) }; loop(0)", evaluator.ErrStepLimit},
+		{evaluator.Limits{MaxDepth: 50}, "let f = fn(n) { f(n + 1) + 1 }; f(0)", evaluator.ErrDepthLimit},
+		{evaluator.Limits{MaxAllocBytes: 1 << 16}, `let f = fn(s) { f(s + s) }; f("ab")`, evaluator.ErrAllocLimit},
+		{evaluator.Limits{MaxSteps: 1000}, "let x = 1 + 2", nil},
+	}
+
+	for _, test := range tests {
+		source := "let m = macro() { " + test.body + "; quote(1) }; m()"
+		_, err := New(WithLimits(test.limits)).Run(context.Background(), source)
+		if !errors.Is(err, test.expected) {
+			t.Errorf("wrong error for %s with %+v. expected=%v, got=%v", test.body, test.limits, test.expected, err)
+		}
+	}
+}
+
+func TestSleepCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := New().Run(ctx, "sleep(60000)")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got=%v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("sleep returned %s after the deadline", elapsed)
+	}
+}
+
+type stoppedClock time.Time
+
+func (clock stoppedClock) Now() time.Time { return time.Time(clock) }
+func (clock stoppedClock) Sleep(ctx context.Context, d time.Duration) error {
+	return nil
+}
+
+func TestWithClock(t *testing.T) {
+	interpreter := New(WithClock(stoppedClock(time.UnixMilli(86400000))))
+
+	result, err := interpreter.Run(context.Background(), `sleep(1000); formatTime(now(), "DateOnly")`)
+	if err != nil {
+		t.Fatalf("Run returned error: %s", err)
+	}
+	if result.Inspect() != "1970-01-02" {
+		t.Errorf("wrong result. expected=%q, got=%q", "1970-01-02", result.Inspect())
+	}
+}
+
+func TestWithFS(t *testing.T) {
+	fsys := fstest.MapFS{"greeting.txt": {Data: []byte("hello")}}
+
+	tests := []struct {
+		options  []Option
+		input    string
+		expected error
+	}{
+		{nil, `readFile("greeting.txt")`, evaluator.ErrFileAccessDisabled},
+		{[]Option{WithFS(evaluator.ReadOnlyFS(fsys))}, `readFile("greeting.txt")`, nil},
+		{[]Option{WithFS(evaluator.ReadOnlyFS(fsys))}, `writeFile("greeting.txt", "bye")`, fs.ErrPermission},
+		{[]Option{WithFS(evaluator.DirFS(t.TempDir()))}, `writeFile("a.txt", "a"); readFile("a.txt")`, nil},
+		{[]Option{WithFS(evaluator.DirFS(t.TempDir()))}, `readFile("../a.txt")`, fs.ErrInvalid},
+	}
+
+	for _, test := range tests {
+		_, err := New(test.options...).Run(context.Background(), test.input)
+		if !errors.Is(err, test.expected) {
+			t.Errorf("wrong error for %s. expected=%v, got=%v", test.input, test.expected, err)
+		}
+	}
+
+	root, outside := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "s.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, input := range []string{`readFile("link/s.txt")`, `writeFile("link/pwned.txt", "x")`} {
+		if _, err := New(WithFS(evaluator.DirFS(root))).Run(context.Background(), input); err == nil {
+			t.Errorf("expected error for %s through a symbolic link out of the root", input)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outside, "pwned.txt")); !os.IsNotExist(err) {
+		t.Errorf("file written outside the root through a symbolic link")
+	}
+}
+
+func TestWithSeed(t *testing.T) {
+	results := []string{}
+	for i := 0; i < 2; i++ {
+		result, err := New(WithSeed(42)).Run(context.Background(), "[random(), randInt(0, 1000), shuffle(range(5))]")
+		if err != nil {
+			t.Fatalf("Run returned error: %s", err)
+		}
+		results = append(results, result.Inspect())
+	}
+
+	if results[0] != results[1] {
+		t.Errorf("same seed gave different results: %s and %s", results[0], results[1])
+	}
+}
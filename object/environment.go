@@ -1,5 +1,7 @@
 package object
 
+import "sort"
+
 func NewEnclosedEnvironment(outer *Environment) *Environment {
 	env := NewEnvironment()
 	env.outer = outer
@@ -28,3 +30,14 @@ func (env *Environment) Set(name string, val Object) Object {
 	env.store[name] = val
 	return val
 }
+
+// Names returns the names bound directly in env, without those of
+// enclosing environments, in sorted order.
+func (env *Environment) Names() []string {
+	names := make([]string, 0, len(env.store))
+	for name := range env.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
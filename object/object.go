@@ -1,18 +1,28 @@
 package object
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"hash/fnv"
+	"io"
+	"io/fs"
+	"math"
+	"math/rand"
 	"monkey/ast"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type ObjectType string
-type BuiltinFunction func(args ...Object) Object
+type BuiltinFunction func(ctx *ExecContext, args ...Object) Object
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	FLOAT_OBJ        = "FLOAT"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
@@ -24,6 +34,8 @@ const (
 	HASH_OBJ         = "HASH"
 	QUOTE_OBJ        = "QUOTE"
 	MACRO_OBJ        = "MACRO"
+	MODULE_OBJ       = "MODULE"
+	REGEX_OBJ        = "REGEX"
 )
 
 type Object interface {
@@ -41,6 +53,23 @@ func (integer *Integer) HashKey() HashKey {
 	return HashKey{Type: integer.Type(), Value: uint64(integer.Value)}
 }
 
+type Float struct {
+	Value float64
+}
+
+func (float *Float) Type() ObjectType { return FLOAT_OBJ }
+func (float *Float) Inspect() string {
+	formatted := strconv.FormatFloat(float.Value, 'g', -1, 64)
+	if !strings.ContainsAny(formatted, ".eIN") {
+		formatted += ".0"
+	}
+	return formatted
+}
+func (float *Float) HashKey() HashKey {
+	// Adding zero turns -0 into 0, so both are the same key.
+	return HashKey{Type: float.Type(), Value: math.Float64bits(float.Value + 0)}
+}
+
 type Boolean struct {
 	Value bool
 }
@@ -74,10 +103,14 @@ func (returnValue *ReturnValue) Inspect() string  { return returnValue.Value.Ins
 
 type Error struct {
 	Message string
+	// Err is the Go error that caused the error, if any.
+	Err error
 }
 
 func (error *Error) Type() ObjectType { return ERROR_OBJ }
 func (error *Error) Inspect() string  { return "ERROR: " + error.Message }
+func (error *Error) Error() string    { return error.Message }
+func (error *Error) Unwrap() error    { return error.Err }
 
 type Function struct {
 	Parameters []*ast.Identifier
@@ -121,6 +154,54 @@ type Builtin struct {
 	Fn BuiltinFunction
 }
 
+// Clock tells the time builtins the current time and lets them wait.
+type Clock interface {
+	Now() time.Time
+	// Sleep waits until d has passed or ctx is done, in which case it
+	// returns ctx.Err().
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// FileSystem gives the file builtins access to files. Names are
+// slash-separated paths as described by fs.ValidPath.
+type FileSystem interface {
+	fs.FS
+	WriteFile(name string, data []byte) error
+}
+
+// ExecContext is passed to builtin functions and gives them access to the
+// evaluation that calls them.
+type ExecContext struct {
+	// Context is done when evaluation has to stop.
+	Context context.Context
+
+	Stdout io.Writer
+	Stderr io.Writer
+	Stdin  *bufio.Reader
+
+	// Rand is the random number generator of the interpreter.
+	Rand *rand.Rand
+
+	// Clock is the source of time of the interpreter.
+	Clock Clock
+
+	// FS holds the files the interpreter may access, or is nil if it may
+	// not access any.
+	FS FileSystem
+
+	// Apply calls the function or builtin fn with args.
+	Apply func(fn Object, args []Object) Object
+
+	// Compile compiles a regular expression, reusing those compiled
+	// before by the interpreter.
+	Compile func(pattern string) (*regexp.Regexp, error)
+
+	// CheckAlloc returns an error if allocating about size more bytes
+	// would exceed the allocation limit of the interpreter. Builtins
+	// building large values call it before allocating them.
+	CheckAlloc func(size int64) error
+}
+
 func (builtin *Builtin) Type() ObjectType { return BUILTIN_OBJ }
 func (builtin *Builtin) Inspect() string  { return "builtin function" }
 
@@ -144,6 +225,24 @@ func (arr *Array) Inspect() string {
 	return out.String()
 }
 
+// Module is a namespace of values whose members are accessed with a dot,
+// as in math.pi.
+type Module struct {
+	Name    string
+	Members map[string]Object
+}
+
+func (module *Module) Type() ObjectType { return MODULE_OBJ }
+func (module *Module) Inspect() string  { return "module " + module.Name }
+
+// Regex is a compiled regular expression.
+type Regex struct {
+	Regexp *regexp.Regexp
+}
+
+func (regex *Regex) Type() ObjectType { return REGEX_OBJ }
+func (regex *Regex) Inspect() string  { return "/" + regex.Regexp.String() + "/" }
+
 type Hashable interface {
 	HashKey() HashKey
 }
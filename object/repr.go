@@ -0,0 +1,127 @@
+package object
+
+import (
+	"bytes"
+	"monkey/ast"
+	"monkey/format"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// reprWidth is the widest a hash may print on one line before Repr puts
+// each of its pairs on a line of its own.
+const reprWidth = 60
+
+// Repr returns a representation of obj for display to programmers. Unlike
+// Inspect, strings are quoted and escaped, hash pairs are sorted by key
+// and nested hashes are indented.
+func Repr(obj Object) string {
+	var out bytes.Buffer
+	writeRepr(&out, obj, 0)
+	return out.String()
+}
+
+func writeRepr(out *bytes.Buffer, obj Object, indent int) {
+	switch obj := obj.(type) {
+	case *String:
+		out.WriteString(strconv.Quote(obj.Value))
+	case *Array:
+		out.WriteString("[")
+		for i, element := range obj.Elements {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			writeRepr(out, element, indent)
+		}
+		out.WriteString("]")
+	case *Hash:
+		writeHashRepr(out, obj, indent)
+	case *Function:
+		literal := &ast.FunctionLiteral{Parameters: obj.Parameters, Body: obj.Body}
+		out.WriteString(indentLines(format.Node(literal), indent))
+	case *Macro:
+		literal := &ast.MacroLiteral{Parameters: obj.Parameters, Body: obj.Body}
+		out.WriteString(indentLines(format.Node(literal), indent))
+	case *Quote:
+		out.WriteString("quote(" + indentLines(format.Node(obj.Node), indent) + ")")
+	case *ReturnValue:
+		writeRepr(out, obj.Value, indent)
+	default:
+		out.WriteString(obj.Inspect())
+	}
+}
+
+func writeHashRepr(out *bytes.Buffer, hash *Hash, indent int) {
+	pairs := hash.SortedPairs()
+	if len(pairs) == 0 {
+		out.WriteString("{}")
+		return
+	}
+
+	// Render every value once, indented for the multi-line form. Without
+	// newlines it reads the same on one line, and rendering again for
+	// either form would take exponential time in the depth of nesting.
+	inline := true
+	entries := make([]string, len(pairs))
+	for i, pair := range pairs {
+		if pair.Value.Type() == HASH_OBJ && len(pair.Value.(*Hash).Pairs) > 0 {
+			inline = false
+		}
+
+		var value bytes.Buffer
+		writeRepr(&value, pair.Value, indent+1)
+		entries[i] = Repr(pair.Key) + ": " + value.String()
+	}
+
+	line := "{" + strings.Join(entries, ", ") + "}"
+	if inline && len(line) <= reprWidth && !strings.Contains(line, "\n") {
+		out.WriteString(line)
+		return
+	}
+
+	padding := strings.Repeat("  ", indent+1)
+	out.WriteString("{\n")
+	for _, entry := range entries {
+		out.WriteString(padding + entry + ",\n")
+	}
+	out.WriteString(strings.Repeat("  ", indent) + "}")
+}
+
+func indentLines(s string, indent int) string {
+	return strings.ReplaceAll(s, "\n", "\n"+strings.Repeat("  ", indent))
+}
+
+// SortedPairs returns the pairs of hash ordered by key: booleans before
+// integers before strings, and by value within each type.
+func (hash *Hash) SortedPairs() []HashPair {
+	pairs := make([]HashPair, 0, len(hash.Pairs))
+	for _, pair := range hash.Pairs {
+		pairs = append(pairs, pair)
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return lessKey(pairs[i].Key, pairs[j].Key)
+	})
+
+	return pairs
+}
+
+func lessKey(left, right Object) bool {
+	if left.Type() != right.Type() {
+		return left.Type() < right.Type()
+	}
+
+	switch left := left.(type) {
+	case *Integer:
+		return left.Value < right.(*Integer).Value
+	case *Float:
+		return left.Value < right.(*Float).Value
+	case *Boolean:
+		return !left.Value && right.(*Boolean).Value
+	case *String:
+		return left.Value < right.(*String).Value
+	default:
+		return left.Inspect() < right.Inspect()
+	}
+}
@@ -0,0 +1,88 @@
+package object
+
+import (
+	"monkey/ast"
+	"monkey/token"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRepr(t *testing.T) {
+	hash := func(pairs ...Object) *Hash {
+		result := &Hash{Pairs: make(map[HashKey]HashPair)}
+		for i := 0; i < len(pairs); i += 2 {
+			key := pairs[i].(Hashable)
+			result.Pairs[key.HashKey()] = HashPair{Key: pairs[i], Value: pairs[i+1]}
+		}
+		return result
+	}
+	str := func(value string) *String { return &String{Value: value} }
+	integer := func(value int64) *Integer { return &Integer{Value: value} }
+
+	tests := []struct {
+		input    Object
+		expected string
+	}{
+		{integer(5), "5"},
+		{str("hello"), `"hello"`},
+		{str("say \"hi\"\n"), `"say \"hi\"\n"`},
+		{&Array{Elements: []Object{str("a"), integer(1), &Null{}}}, `["a", 1, null]`},
+		{hash(), "{}"},
+		{hash(str("b"), integer(2), str("a"), integer(1)), `{"a": 1, "b": 2}`},
+		{hash(str("x"), integer(1), integer(2), integer(2), &Boolean{Value: true}, integer(3)), `{true: 3, 2: 2, "x": 1}`},
+		{
+			hash(str("name"), str("monkey"), str("meta"), hash(str("version"), integer(1))),
+			"{\n  \"meta\": {\"version\": 1},\n  \"name\": \"monkey\",\n}",
+		},
+		{
+			hash(str("outer"), hash(str("inner"), hash(str("x"), integer(1)))),
+			"{\n  \"outer\": {\n    \"inner\": {\"x\": 1},\n  },\n}",
+		},
+		{
+			&Function{
+				Parameters: []*ast.Identifier{{Value: "x"}},
+				Body: &ast.BlockStatement{
+					Statements: []ast.Statement{
+						&ast.ExpressionStatement{
+							Expression: &ast.InfixExpression{
+								Token:    token.Token{Type: token.PLUS, Literal: "+"},
+								Left:     &ast.Identifier{Value: "x"},
+								Operator: "+",
+								Right:    &ast.IntegerLiteral{Value: 1},
+							},
+						},
+					},
+				},
+			},
+			"fn(x) {\n    x + 1\n}",
+		},
+		{&Error{Message: "boom"}, "ERROR: boom"},
+	}
+
+	for _, test := range tests {
+		if actual := Repr(test.input); actual != test.expected {
+			t.Errorf("Repr wrong.\nexpected=%q\ngot=     %q", test.expected, actual)
+		}
+	}
+}
+
+func TestReprDeepHash(t *testing.T) {
+	var value Object = &Integer{Value: 1}
+	for i := 0; i < 25; i++ {
+		key := &String{Value: "a"}
+		value = &Hash{Pairs: map[HashKey]HashPair{key.HashKey(): {Key: key, Value: value}}}
+	}
+
+	done := make(chan string)
+	go func() { done <- Repr(value) }()
+
+	select {
+	case repr := <-done:
+		if !strings.HasPrefix(repr, "{\n  \"a\": {\n    \"a\": {") {
+			t.Errorf("wrong Repr of nested hash. got=%q", repr[:40])
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Repr of a 25-deep hash did not finish")
+	}
+}
@@ -31,6 +31,7 @@ var precedences = map[token.Type]int{
 	token.ASTERISK: PRODUCT,
 	token.LPAREN:   CALL,
 	token.LBRACKET: INDEX,
+	token.DOT:      INDEX,
 }
 
 type Parser struct {
@@ -55,6 +56,8 @@ func New(l *lexer.Lexer) *Parser {
 	parser.prefixParseFns = make(map[token.Type]prefixParseFn)
 	parser.registerPrefix(token.IDENT, parser.parseIdentifier)
 	parser.registerPrefix(token.INT, parser.parseIntegerLiteral)
+	parser.registerPrefix(token.FLOAT, parser.parseFloatLiteral)
+	parser.registerPrefix(token.IMPORT, parser.parseImportExpression)
 	parser.registerPrefix(token.BANG, parser.parsePrefixExpression)
 	parser.registerPrefix(token.MINUS, parser.parsePrefixExpression)
 	parser.registerPrefix(token.TRUE, parser.parseBoolean)
@@ -78,6 +81,7 @@ func New(l *lexer.Lexer) *Parser {
 	parser.registerInfix(token.GT, parser.parseInfixExpression)
 	parser.registerInfix(token.LPAREN, parser.parseCallExpression)
 	parser.registerInfix(token.LBRACKET, parser.parseIndexExpression)
+	parser.registerInfix(token.DOT, parser.parseMemberExpression)
 
 	parser.nextToken()
 	parser.nextToken()
@@ -107,18 +111,21 @@ func (parser *Parser) noPrefixParseFnError(tokenType token.Type) {
 	parser.errors = append(parser.errors, message)
 }
 
-func (parser *Parser) currPrecedence() int {
-	if precedence, ok := precedences[parser.currToken.Type]; ok {
+// Precedence returns how tightly an infix operator of the given token
+// type binds, or LOWEST if the token is not an infix operator.
+func Precedence(tokenType token.Type) int {
+	if precedence, ok := precedences[tokenType]; ok {
 		return precedence
 	}
 	return LOWEST
 }
 
+func (parser *Parser) currPrecedence() int {
+	return Precedence(parser.currToken.Type)
+}
+
 func (parser *Parser) peekPrecedence() int {
-	if precedence, ok := precedences[parser.peekToken.Type]; ok {
-		return precedence
-	}
-	return LOWEST
+	return Precedence(parser.peekToken.Type)
 }
 
 func (parser *Parser) nextToken() {
@@ -182,6 +189,11 @@ func (parser *Parser) parseStatement() ast.Statement {
 		return parser.parseLetStatement()
 	case token.RETURN:
 		return parser.parseReturnStatement()
+	case token.IMPORT:
+		if parser.peekTokenIs(token.STRING) {
+			return parser.parseImportStatement()
+		}
+		return parser.parseExpressionStatement()
 	default:
 		return parser.parseExpressionStatement()
 	}
@@ -211,6 +223,32 @@ func (parser *Parser) parseLetStatement() *ast.LetStatement {
 	return statement
 }
 
+func (parser *Parser) parseImportStatement() *ast.ImportStatement {
+	statement := &ast.ImportStatement{Token: parser.currToken}
+
+	parser.nextToken()
+	statement.Path = &ast.StringLiteral{Token: parser.currToken, Value: parser.currToken.Literal}
+
+	// "as" is only special here, so it remains usable as a name.
+	if !parser.peekTokenIs(token.IDENT) || parser.peekToken.Literal != "as" {
+		message := fmt.Sprintf("expected next token to be as, got %s instead", parser.peekToken.Type)
+		parser.errors = append(parser.errors, message)
+		return nil
+	}
+	parser.nextToken()
+
+	if !parser.expectPeek(token.IDENT) {
+		return nil
+	}
+	statement.Name = &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal}
+
+	for parser.peekTokenIs(token.SEMICOLON) {
+		parser.nextToken()
+	}
+
+	return statement
+}
+
 func (parser *Parser) parseReturnStatement() *ast.ReturnStatement {
 	statement := &ast.ReturnStatement{Token: parser.currToken}
 
@@ -413,10 +451,52 @@ func (parser *Parser) parseIndexExpression(array ast.Expression) ast.Expression
 	return expression
 }
 
+func (parser *Parser) parseImportExpression() ast.Expression {
+	expression := &ast.ImportExpression{Token: parser.currToken}
+
+	if !parser.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	parser.nextToken()
+	expression.Path = parser.parseExpression(LOWEST)
+
+	if !parser.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	return expression
+}
+
+func (parser *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
+	expression := &ast.MemberExpression{Token: parser.currToken, Left: left}
+
+	if !parser.expectPeek(token.IDENT) {
+		return nil
+	}
+	expression.Member = &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal}
+
+	return expression
+}
+
 func (parser *Parser) parseIdentifier() ast.Expression {
 	return &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal}
 }
 
+func (parser *Parser) parseFloatLiteral() ast.Expression {
+	literal := &ast.FloatLiteral{Token: parser.currToken}
+
+	value, err := strconv.ParseFloat(parser.currToken.Literal, 64)
+	if err != nil {
+		message := fmt.Sprintf("could not parse %q as float", parser.currToken.Literal)
+		parser.errors = append(parser.errors, message)
+		return nil
+	}
+	literal.Value = value
+
+	return literal
+}
+
 func (parser *Parser) parseIntegerLiteral() ast.Expression {
 	literal := &ast.IntegerLiteral{Token: parser.currToken}
 
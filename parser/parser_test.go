@@ -142,6 +142,75 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"2.5;", 2.5},
+		{"1e3;", 1000},
+		{"0.125e-1;", 0.0125},
+	}
+
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		statement := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := statement.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("statement.Expression is not *ast.FloatLiteral. got=%T", statement.Expression)
+		}
+
+		if literal.Value != test.expected {
+			t.Errorf("literal.Value is not %g. got=%g", test.expected, literal.Value)
+		}
+	}
+}
+
+func TestImportParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "lib/util.mk" as util`, `import lib/util.mk as util;`},
+		{`import "a.mk" as as;`, `import a.mk as as;`},
+		{`let m = import("lib/" + name)`, `let m = import((lib/ + name));`},
+		{`import("m.mk").f(1)`, `(import(m.mk).f)(1)`},
+	}
+
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != test.expected {
+			t.Errorf("wrong program for %s. expected=%q, got=%q", test.input, test.expected, actual)
+		}
+	}
+
+	errors := map[string]string{
+		`import "a.mk"`:      "expected next token to be as, got EOF instead",
+		`import "a.mk" as 1`: "expected next token to be IDENT, got INT instead",
+		`import "a.mk" to a`: "expected next token to be as, got IDENT instead",
+		`import "a.mk", "b"`: "expected next token to be as, got , instead",
+	}
+
+	for input, expected := range errors {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != expected {
+			t.Errorf("wrong errors for %s. expected=%q, got=%q", input, expected, p.Errors())
+		}
+	}
+}
+
 func TestBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input         string
@@ -349,6 +418,14 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"-(5 + 5)",
 			"(-(5 + 5))",
 		},
+		{
+			"-math.pi * a.b.c",
+			"((-(math.pi)) * ((a.b).c))",
+		},
+		{
+			"m.f(x)[0].y",
+			"(((m.f)(x)[0]).y)",
+		},
 		{
 			"!(true == true)",
 			"(!(true == true))",
@@ -0,0 +1,141 @@
+package readline
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultHistorySize is the number of entries a History keeps by default.
+const DefaultHistorySize = 1000
+
+// History is a list of previously entered lines, optionally persisted to
+// a file with one entry per line. Entries may span several lines; in the
+// file their newlines and backslashes are escaped with a backslash.
+type History struct {
+	entries []string
+	max     int
+	file    string
+}
+
+// NewHistory returns an empty History that is not persisted.
+func NewHistory() *History {
+	return &History{max: DefaultHistorySize}
+}
+
+// LoadHistory returns the History stored in file. A missing file yields
+// an empty History; entries added later are appended to the file.
+func LoadHistory(file string) (*History, error) {
+	history := NewHistory()
+	history.file = file
+
+	f, err := os.Open(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		history.add(unescapeEntry(scanner.Text()))
+	}
+
+	return history, scanner.Err()
+}
+
+// DefaultHistoryFile returns the location of the REPL history in the
+// user's configuration directory.
+func DefaultHistoryFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "monkey", "history"), nil
+}
+
+// Add appends line to the history, unless it is blank or repeats the
+// most recent entry.
+func (history *History) Add(line string) error {
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+	if len(history.entries) > 0 && history.entries[len(history.entries)-1] == line {
+		return nil
+	}
+
+	history.add(line)
+
+	if history.file == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(history.file), 0o700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(history.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(entryEscaper.Replace(line) + "\n")
+	return err
+}
+
+var entryEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// unescapeEntry reverses entryEscaper. Other backslashes, as written by
+// versions that did not escape entries, are kept.
+func unescapeEntry(escaped string) string {
+	var entry strings.Builder
+	for i := 0; i < len(escaped); i++ {
+		if escaped[i] == '\\' && i+1 < len(escaped) {
+			switch escaped[i+1] {
+			case 'n':
+				entry.WriteByte('\n')
+				i += 1
+				continue
+			case '\\':
+				entry.WriteByte('\\')
+				i += 1
+				continue
+			}
+		}
+		entry.WriteByte(escaped[i])
+	}
+	return entry.String()
+}
+
+func (history *History) add(line string) {
+	history.entries = append(history.entries, line)
+	if len(history.entries) > history.max {
+		history.entries = history.entries[len(history.entries)-history.max:]
+	}
+}
+
+// Len returns the number of entries.
+func (history *History) Len() int {
+	return len(history.entries)
+}
+
+// At returns the entry at index i, with 0 being the oldest.
+func (history *History) At(i int) string {
+	return history.entries[i]
+}
+
+// search returns the index of the newest entry before index from that
+// contains query, or -1.
+func (history *History) search(query string, from int) int {
+	for i := from - 1; i >= 0; i-- {
+		if strings.Contains(history.entries[i], query) {
+			return i
+		}
+	}
+	return -1
+}
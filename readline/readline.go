@@ -0,0 +1,426 @@
+// Package readline implements an interactive line editor for terminals,
+// with cursor movement, history recall and search, and tab completion.
+package readline
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ErrInterrupted is returned by ReadLine when the user presses Ctrl-C.
+var ErrInterrupted = errors.New("readline: interrupted")
+
+// A Completer returns the candidates that complete word, the identifier
+// in front of the cursor. line is the whole line being edited.
+type Completer func(line, word string) []string
+
+// Editor reads lines from a terminal.
+type Editor struct {
+	fd  int
+	in  *bufio.Reader
+	out io.Writer
+
+	History  *History
+	Complete Completer
+
+	// Highlight, if set, decorates the line with terminal escape codes
+	// before it is drawn. It must not change the visible text.
+	Highlight func(line string) string
+
+	// Per line editing state.
+	prompt    string
+	buf       []rune
+	pos       int
+	lastKey   rune
+	browsing  int    // Index of the history entry shown, or History.Len()
+	scratch   []rune // The line being edited before browsing the history
+	searching bool
+	query     []rune
+	match     int
+}
+
+// New returns an Editor for the terminal in. It fails if in is not a
+// terminal, in which case callers should read lines without editing.
+func New(in *os.File, out io.Writer) (*Editor, error) {
+	fd := int(in.Fd())
+	if !IsTerminal(fd) {
+		return nil, fmt.Errorf("readline: %s is not a terminal", in.Name())
+	}
+
+	return &Editor{fd: fd, in: bufio.NewReader(in), out: out, History: NewHistory()}, nil
+}
+
+// ReadLine shows prompt and returns the line the user entered, without
+// the newline. It returns io.EOF when the user presses Ctrl-D on an empty
+// line and ErrInterrupted when the user presses Ctrl-C.
+func (editor *Editor) ReadLine(prompt string) (string, error) {
+	restore, err := makeRaw(editor.fd)
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+
+	return editor.edit(prompt)
+}
+
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlG     = 7
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyLineFeed  = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlR     = 18
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+
+	// Keys sent as escape sequences are mapped to private use code points.
+	keyUp = unicode.MaxRune + iota
+	keyDown
+	keyRight
+	keyLeft
+	keyHome
+	keyEnd
+	keyDelete
+	keyUnknown
+)
+
+func (editor *Editor) edit(prompt string) (string, error) {
+	editor.prompt = prompt
+	editor.buf = editor.buf[:0]
+	editor.pos = 0
+	editor.lastKey = 0
+	editor.browsing = editor.History.Len()
+	editor.searching = false
+	editor.refresh()
+
+	for {
+		key, err := editor.readKey()
+		if err != nil {
+			return "", err
+		}
+
+		if editor.searching {
+			done := editor.searchKey(key)
+			if !done {
+				editor.lastKey = key
+				continue
+			}
+			if key == keyEnter || key == keyLineFeed {
+				return editor.finish(), nil
+			}
+			if key != keyCtrlC {
+				editor.lastKey = key
+				continue
+			}
+		}
+
+		switch key {
+		case keyEnter, keyLineFeed:
+			return editor.finish(), nil
+		case keyCtrlC:
+			editor.pos = len(editor.buf)
+			editor.refresh()
+			io.WriteString(editor.out, "^C\r\n")
+			return "", ErrInterrupted
+		case keyCtrlD:
+			if len(editor.buf) == 0 {
+				io.WriteString(editor.out, "\r\n")
+				return "", io.EOF
+			}
+			editor.delete(editor.pos, editor.pos+1)
+		case keyCtrlA, keyHome:
+			editor.pos = 0
+		case keyCtrlE, keyEnd:
+			editor.pos = len(editor.buf)
+		case keyCtrlB, keyLeft:
+			if editor.pos > 0 {
+				editor.pos -= 1
+			}
+		case keyCtrlF, keyRight:
+			if editor.pos < len(editor.buf) {
+				editor.pos += 1
+			}
+		case keyCtrlH, keyBackspace:
+			if editor.pos > 0 {
+				editor.delete(editor.pos-1, editor.pos)
+			}
+		case keyDelete:
+			editor.delete(editor.pos, editor.pos+1)
+		case keyCtrlK:
+			editor.delete(editor.pos, len(editor.buf))
+		case keyCtrlU:
+			editor.delete(0, editor.pos)
+		case keyCtrlW:
+			start := editor.pos
+			for start > 0 && unicode.IsSpace(editor.buf[start-1]) {
+				start -= 1
+			}
+			for start > 0 && !unicode.IsSpace(editor.buf[start-1]) {
+				start -= 1
+			}
+			editor.delete(start, editor.pos)
+		case keyCtrlL:
+			io.WriteString(editor.out, "\x1b[H\x1b[2J")
+		case keyCtrlP, keyUp:
+			editor.browse(-1)
+		case keyCtrlN, keyDown:
+			editor.browse(1)
+		case keyCtrlR:
+			editor.searching = true
+			editor.query = editor.query[:0]
+			editor.match = -1
+		case keyTab:
+			editor.complete()
+		default:
+			if unicode.IsPrint(key) {
+				editor.insert(key)
+			}
+		}
+
+		editor.lastKey = key
+		editor.refresh()
+	}
+}
+
+func (editor *Editor) finish() string {
+	editor.pos = len(editor.buf)
+	editor.searching = false
+	editor.refresh()
+	io.WriteString(editor.out, "\r\n")
+	return string(editor.buf)
+}
+
+// readKey reads one key press, decoding the escape sequences terminals
+// send for arrow and editing keys.
+func (editor *Editor) readKey() (rune, error) {
+	key, _, err := editor.in.ReadRune()
+	if err != nil || key != keyEscape {
+		return key, err
+	}
+
+	next, _, err := editor.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if next != '[' && next != 'O' {
+		return keyUnknown, nil
+	}
+
+	code, _, err := editor.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+
+	switch code {
+	case 'A':
+		return keyUp, nil
+	case 'B':
+		return keyDown, nil
+	case 'C':
+		return keyRight, nil
+	case 'D':
+		return keyLeft, nil
+	case 'H':
+		return keyHome, nil
+	case 'F':
+		return keyEnd, nil
+	}
+
+	if code < '0' || code > '9' {
+		return keyUnknown, nil
+	}
+
+	// Sequences like ESC [ 3 ~ end with a tilde.
+	for {
+		last, _, err := editor.in.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+		if last == '~' {
+			break
+		}
+		if last < '0' || last > '9' {
+			return keyUnknown, nil
+		}
+	}
+
+	switch code {
+	case '1', '7':
+		return keyHome, nil
+	case '3':
+		return keyDelete, nil
+	case '4', '8':
+		return keyEnd, nil
+	default:
+		return keyUnknown, nil
+	}
+}
+
+func (editor *Editor) insert(runes ...rune) {
+	tail := append([]rune{}, editor.buf[editor.pos:]...)
+	editor.buf = append(append(editor.buf[:editor.pos], runes...), tail...)
+	editor.pos += len(runes)
+}
+
+func (editor *Editor) delete(from, to int) {
+	if to > len(editor.buf) {
+		to = len(editor.buf)
+	}
+	if from >= to {
+		return
+	}
+	editor.buf = append(editor.buf[:from], editor.buf[to:]...)
+	editor.pos = from
+}
+
+func (editor *Editor) setLine(line []rune) {
+	editor.buf = append(editor.buf[:0], line...)
+	editor.pos = len(editor.buf)
+}
+
+// browse moves through the history by delta entries, keeping the line
+// being edited to return to after the newest entry.
+func (editor *Editor) browse(delta int) {
+	target := editor.browsing + delta
+	if target < 0 || target > editor.History.Len() {
+		return
+	}
+
+	if editor.browsing == editor.History.Len() {
+		editor.scratch = append(editor.scratch[:0], editor.buf...)
+	}
+	editor.browsing = target
+
+	if target == editor.History.Len() {
+		editor.setLine(editor.scratch)
+	} else {
+		editor.setLine([]rune(editor.History.At(target)))
+	}
+}
+
+// searchKey handles a key press during reverse search and reports whether
+// the search is done. Keys that end the search leave the match in the
+// line being edited.
+func (editor *Editor) searchKey(key rune) bool {
+	switch key {
+	case keyCtrlR:
+		from := editor.match
+		if from < 0 {
+			from = editor.History.Len()
+		}
+		if match := editor.History.search(string(editor.query), from); match >= 0 {
+			editor.match = match
+		}
+	case keyCtrlH, keyBackspace:
+		if len(editor.query) > 0 {
+			editor.query = editor.query[:len(editor.query)-1]
+			editor.match = editor.History.search(string(editor.query), editor.History.Len())
+		}
+	case keyCtrlG, keyCtrlC:
+		editor.searching = false
+		editor.refresh()
+		return key == keyCtrlC
+	default:
+		if unicode.IsPrint(key) {
+			editor.query = append(editor.query, key)
+			editor.match = editor.History.search(string(editor.query), editor.History.Len())
+			break
+		}
+
+		editor.searching = false
+		if editor.match >= 0 {
+			editor.setLine([]rune(editor.History.At(editor.match)))
+		}
+		editor.refresh()
+		return true
+	}
+
+	editor.refresh()
+	return false
+}
+
+// complete completes the word in front of the cursor. A unique candidate
+// is inserted in full, several candidates are completed to their longest
+// common prefix and listed when Tab is pressed twice.
+func (editor *Editor) complete() {
+	if editor.Complete == nil {
+		return
+	}
+
+	start := editor.pos
+	for start > 0 && isWordRune(editor.buf[start-1]) {
+		start -= 1
+	}
+	word := string(editor.buf[start:editor.pos])
+
+	candidates := editor.Complete(string(editor.buf), word)
+	if len(candidates) == 0 {
+		return
+	}
+	sort.Strings(candidates)
+
+	prefix := candidates[0]
+	for _, candidate := range candidates[1:] {
+		for !strings.HasPrefix(candidate, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	if len(prefix) > len(word) {
+		editor.insert([]rune(prefix[len(word):])...)
+		return
+	}
+
+	if len(candidates) > 1 && editor.lastKey == keyTab {
+		io.WriteString(editor.out, "\r\n"+strings.Join(candidates, "  ")+"\r\n")
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r == ':' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// refresh redraws the prompt and the line and places the cursor.
+func (editor *Editor) refresh() {
+	prompt, line, cursor := editor.prompt, string(editor.buf), editor.pos
+
+	if editor.searching {
+		prompt = fmt.Sprintf("(reverse-i-search)`%s': ", string(editor.query))
+		line, cursor = "", 0
+		if editor.match >= 0 {
+			match := editor.History.At(editor.match)
+			line = match
+			cursor = len([]rune(match[:strings.Index(match, string(editor.query))]))
+		}
+	} else if editor.Highlight != nil {
+		line = editor.Highlight(line)
+	}
+
+	// Show the newlines of multi-line entries recalled from the history
+	// as one character each, so the input stays on one line.
+	line = strings.ReplaceAll(line, "\n", "↵")
+
+	column := len([]rune(prompt)) + cursor
+	fmt.Fprintf(editor.out, "\r%s%s\x1b[K\r", prompt, line)
+	if column > 0 {
+		fmt.Fprintf(editor.out, "\x1b[%dC", column)
+	}
+}
@@ -0,0 +1,153 @@
+package readline
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestEditor(keys string, history ...string) *Editor {
+	editor := &Editor{
+		in:      bufio.NewReader(strings.NewReader(keys)),
+		out:     io.Discard,
+		History: NewHistory(),
+	}
+	for _, entry := range history {
+		editor.History.Add(entry)
+	}
+	return editor
+}
+
+func TestEdit(t *testing.T) {
+	tests := []struct {
+		keys     string
+		history  []string
+		expected string
+	}{
+		{"let x = 5;\r", nil, "let x = 5;"},
+		{"abc\x7f\x7fd\r", nil, "ad"},
+		{"ac\x1b[Db\r", nil, "abc"},
+		{"bc\x01a\x05d\r", nil, "abcd"},
+		{"abc\x1b[H\x1b[3~\r", nil, "bc"},
+		{"hello world\x17there\r", nil, "hello there"},
+		{"abc\x02\x02\x0b\r", nil, "a"},
+		{"abc\x02\x15\r", nil, "c"},
+		{"\x1b[A\r", []string{"first", "second"}, "second"},
+		{"\x1b[A\x1b[A\r", []string{"first", "second"}, "first"},
+		{"draft\x1b[A\x1b[B\r", []string{"first"}, "draft"},
+		{"\x10\x10\x10\x0e\r", []string{"first", "second"}, "second"},
+		{"\x12fi\r", []string{"first", "second", "fifth"}, "fifth"},
+		{"\x12fi\x12\r", []string{"first", "second", "fifth"}, "first"},
+		{"\x12sec\x1b[C!\r", []string{"first", "second"}, "second!"},
+		{"x\x12sec\x07\r", []string{"second"}, "x"},
+		{"日本\x7f語\r", nil, "日語"},
+	}
+
+	for _, test := range tests {
+		editor := newTestEditor(test.keys, test.history...)
+
+		line, err := editor.edit(">> ")
+		if err != nil {
+			t.Errorf("edit(%q) returned error: %s", test.keys, err)
+			continue
+		}
+
+		if line != test.expected {
+			t.Errorf("edit(%q) wrong. expected=%q, got=%q", test.keys, test.expected, line)
+		}
+	}
+}
+
+func TestEditEndOfInput(t *testing.T) {
+	tests := []struct {
+		keys     string
+		expected error
+	}{
+		{"\x04", io.EOF},
+		{"abc\x03", ErrInterrupted},
+		{"abc", io.EOF},
+	}
+
+	for _, test := range tests {
+		editor := newTestEditor(test.keys)
+
+		_, err := editor.edit(">> ")
+		if err != test.expected {
+			t.Errorf("edit(%q) wrong error. expected=%v, got=%v", test.keys, test.expected, err)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	words := []string{"let", "len", "last", "puts"}
+	completer := func(line, word string) []string {
+		candidates := []string{}
+		for _, candidate := range words {
+			if strings.HasPrefix(candidate, word) {
+				candidates = append(candidates, candidate)
+			}
+		}
+		return candidates
+	}
+
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"pu\t(1)\r", "puts(1)"},
+		{"x + la\t\r", "x + last"},
+		{"l\t\r", "l"},
+		{"le\t\r", "le"},
+		{"le\tt\r", "let"},
+		{"zz\t\r", "zz"},
+	}
+
+	for _, test := range tests {
+		editor := newTestEditor(test.keys)
+		editor.Complete = completer
+
+		line, err := editor.edit(">> ")
+		if err != nil {
+			t.Errorf("edit(%q) returned error: %s", test.keys, err)
+			continue
+		}
+
+		if line != test.expected {
+			t.Errorf("edit(%q) wrong. expected=%q, got=%q", test.keys, test.expected, line)
+		}
+	}
+}
+
+func TestHistoryPersistence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "monkey", "history")
+
+	history, err := LoadHistory(file)
+	if err != nil {
+		t.Fatalf("LoadHistory returned error: %s", err)
+	}
+
+	for _, line := range []string{"let x = 1;", "", "let x = 1;", "let f = fn() { // one\n1\n};", `"C:\" + "\n"`} {
+		if err := history.Add(line); err != nil {
+			t.Fatalf("Add returned error: %s", err)
+		}
+	}
+
+	contents, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("history file not written: %s", err)
+	}
+	if string(contents) != "let x = 1;\nlet f = fn() { // one\\n1\\n};\n"+`"C:\\" + "\\n"`+"\n" {
+		t.Errorf("wrong history file contents. got=%q", contents)
+	}
+
+	reloaded, err := LoadHistory(file)
+	if err != nil {
+		t.Fatalf("LoadHistory returned error: %s", err)
+	}
+	if reloaded.Len() != 3 || reloaded.At(1) != "let f = fn() { // one\n1\n};" || reloaded.At(2) != `"C:\" + "\n"` {
+		t.Errorf("wrong history after reload. got=%v", reloaded.entries)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package readline
+
+import "errors"
+
+// IsTerminal reports whether fd refers to a terminal. Line editing is not
+// supported on this platform, so it always reports false.
+func IsTerminal(fd int) bool {
+	return false
+}
+
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("readline: raw mode is not supported on this platform")
+}
@@ -0,0 +1,95 @@
+package repl
+
+import (
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/token"
+	"sort"
+	"strings"
+)
+
+const (
+	colorReset    = "\x1b[0m"
+	colorKeyword  = "\x1b[35m"
+	colorConstant = "\x1b[33m"
+	colorString   = "\x1b[32m"
+	colorBuiltin  = "\x1b[36m"
+	colorComment  = "\x1b[90m"
+	colorError    = "\x1b[31m"
+)
+
+type span struct {
+	start, end int
+	color      string
+}
+
+// highlight returns source with ANSI color codes around keywords,
+// literals, builtins and comments, as recognized by the lexer.
+func highlight(source string) string {
+	builtins := make(map[string]bool)
+	for _, name := range evaluator.BuiltinNames() {
+		builtins[name] = true
+	}
+
+	spans := []span{}
+	l := lexer.New(source)
+
+	for {
+		tok := l.NextToken()
+		if tok.Type == token.EOF {
+			break
+		}
+
+		start := tok.Pos.Offset
+		end := start + len(tok.Literal)
+
+		switch tok.Type {
+		case token.STRING:
+			end = stringEnd(source, start, tok.Literal)
+			spans = append(spans, span{start, end, colorString})
+		case token.LET, token.FUNCTION, token.MACRO, token.IF, token.ELSE, token.RETURN, token.IMPORT:
+			spans = append(spans, span{start, end, colorKeyword})
+		case token.INT, token.FLOAT, token.TRUE, token.FALSE:
+			spans = append(spans, span{start, end, colorConstant})
+		case token.IDENT:
+			if builtins[tok.Literal] {
+				spans = append(spans, span{start, end, colorBuiltin})
+			} else if tok.Literal == "null" {
+				spans = append(spans, span{start, end, colorConstant})
+			}
+		}
+	}
+	spans = append(spans, commentSpans(l.Comments())...)
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var out strings.Builder
+	written := 0
+	for _, span := range spans {
+		out.WriteString(source[written:span.start])
+		out.WriteString(span.color + source[span.start:span.end] + colorReset)
+		written = span.end
+	}
+	out.WriteString(source[written:])
+
+	return out.String()
+}
+
+func commentSpans(comments []token.Token) []span {
+	spans := []span{}
+	for _, comment := range comments {
+		start := comment.Pos.Offset
+		spans = append(spans, span{start, start + len(comment.Literal), colorComment})
+	}
+	return spans
+}
+
+// stringEnd returns the offset after the string token starting at start,
+// including its closing quote unless the string is unterminated.
+func stringEnd(source string, start int, literal string) int {
+	end := start + 1 + len(literal)
+	if end < len(source) && source[end] == '"' {
+		end += 1
+	}
+	return end
+}
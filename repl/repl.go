@@ -2,48 +2,438 @@ package repl
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
+	"monkey/ast"
 	"monkey/evaluator"
+	"monkey/format"
 	"monkey/object"
+	"monkey/token"
+	"os"
+	"os/signal"
+	"strings"
 
 	"monkey/lexer"
 	"monkey/parser"
+	"monkey/readline"
 )
 
 const PROMPT = ">> "
+const CONTINUATION_PROMPT = ".. "
 
+// Start runs the REPL with read-only access to the files of the current
+// directory.
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
-	macroEnv := object.NewEnvironment()
+	StartWithFS(in, out, evaluator.ReadOnlyFS(evaluator.DirFS(".")))
+}
+
+// StartWithFS runs the REPL with access to the files of fsys.
+func StartWithFS(in io.Reader, out io.Writer, fsys object.FileSystem) {
+	session := newSession(out)
+	session.runtime.FS = fsys
+
+	// Builtins reading input share the buffer of the line reader, so lines
+	// it read ahead are not lost to them.
+	buffered := bufio.NewReader(in)
+	session.runtime.Stdin = buffered
+	reader := session.newLineReader(in, buffered)
 
 	for {
-		fmt.Fprintf(out, PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
+		input, err := readInput(reader)
+		if err == readline.ErrInterrupted {
+			continue
+		}
+		if err != nil {
 			return
 		}
 
-		line := scanner.Text()
-		l := lexer.New(line)
-		p := parser.New(l)
+		if session.history != nil {
+			session.history.Add(input)
+		}
 
-		program := p.ParseProgram()
-		if len(p.Errors()) != 0 {
-			printParserErrors(out, p.Errors())
+		if strings.HasPrefix(strings.TrimSpace(input), ":") {
+			if quit := session.command(strings.TrimSpace(input)); quit {
+				return
+			}
 			continue
 		}
 
-		evaluator.DefineMacros(program, macroEnv)
-		expanded := evaluator.ExpandMacros(program, macroEnv)
+		session.eval(input)
+	}
+}
+
+// lineReader reads input one line at a time, showing a prompt first.
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+type bufferedReader struct {
+	reader *bufio.Reader
+	out    io.Writer
+}
+
+func (reader *bufferedReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(reader.out, prompt)
+	line, err := reader.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+// newLineReader returns a line editor with history and completion if in
+// is a terminal, and a plain line reader of buffered otherwise.
+func (session *session) newLineReader(in io.Reader, buffered *bufio.Reader) lineReader {
+	file, ok := in.(*os.File)
+	if !ok {
+		return &bufferedReader{reader: buffered, out: session.out}
+	}
+
+	editor, err := readline.New(file, session.out)
+	if err != nil {
+		return &bufferedReader{reader: buffered, out: session.out}
+	}
+
+	editor.Complete = session.complete
+	if session.color {
+		editor.Highlight = highlight
+	}
+	if historyFile, err := readline.DefaultHistoryFile(); err == nil {
+		if history, err := readline.LoadHistory(historyFile); err == nil {
+			editor.History = history
+		}
+	}
+	session.history = editor.History
+	session.interactive = true
+
+	return editor
+}
+
+// readInput reads lines until they form a complete input. An empty line
+// submits the input even if it is still incomplete.
+func readInput(reader lineReader) (string, error) {
+	input, err := reader.ReadLine(PROMPT)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(strings.TrimSpace(input), ":") {
+		return input, nil
+	}
+
+	for isIncomplete(input) {
+		line, err := reader.ReadLine(CONTINUATION_PROMPT)
+		if err == readline.ErrInterrupted {
+			return "", err
+		}
+		if err != nil || line == "" {
+			break
+		}
+		input += "\n" + line
+	}
+
+	return input, nil
+}
+
+// isIncomplete reports whether input ends inside a string or an open
+// parenthesis, bracket or brace, or ends with an operator that still
+// expects an operand.
+func isIncomplete(input string) bool {
+	l := lexer.New(input)
+
+	depth := 0
+	var last token.Token
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LPAREN, token.LBRACKET, token.LBRACE:
+			depth += 1
+		case token.RPAREN, token.RBRACKET, token.RBRACE:
+			depth -= 1
+		case token.STRING:
+			if tok.Pos.Offset+len(tok.Literal)+1 >= len(input) {
+				return true
+			}
+		}
+		last = tok
+	}
+
+	if depth > 0 {
+		return true
+	}
+
+	switch last.Type {
+	case token.ASSIGN, token.PLUS, token.MINUS, token.BANG, token.ASTERISK, token.SLASH,
+		token.LT, token.GT, token.EQ, token.NOT_EQ, token.COMMA, token.COLON,
+		token.LET, token.RETURN, token.IF, token.ELSE, token.FUNCTION, token.MACRO:
+		return true
+	default:
+		return false
+	}
+}
+
+type session struct {
+	out      io.Writer
+	env      *object.Environment
+	macroEnv *object.Environment
+	runtime  *evaluator.Runtime
+	history  *readline.History
+	color    bool
+
+	// interactive is set when reading from a terminal, where Ctrl-C
+	// interrupts a running evaluation instead of ending the process.
+	interactive bool
+
+	// inputs holds the source of every input evaluated without error
+	// since the session started or was last reset.
+	inputs []string
+}
+
+func newSession(out io.Writer) *session {
+	session := &session{
+		out:      out,
+		env:      object.NewEnvironment(),
+		macroEnv: object.NewEnvironment(),
+		runtime:  evaluator.NewRuntime(),
+		color:    useColor(out),
+	}
+	session.runtime.Stdout = out
+	session.runtime.FS = evaluator.ReadOnlyFS(evaluator.DirFS("."))
+	return session
+}
+
+// useColor reports whether out is a terminal and the user has not opted
+// out of colors by setting NO_COLOR.
+func useColor(out io.Writer) bool {
+	file, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	return readline.IsTerminal(int(file.Fd()))
+}
+
+func (session *session) eval(input string) {
+	l := lexer.New(input)
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(session.out, p.Errors())
+		return
+	}
+
+	ctx := context.Background()
+	if session.interactive {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+	}
+
+	evaluator.DefineMacros(program, session.macroEnv)
+	expanded, err := session.runtime.ExpandMacros(ctx, program, session.macroEnv)
+	if err != nil {
+		io.WriteString(session.out, session.repr(err)+"\n")
+		return
+	}
+
+	evaluated := session.runtime.Eval(ctx, expanded, session.env)
+	if evaluated != nil {
+		io.WriteString(session.out, session.repr(evaluated)+"\n")
+	}
+
+	if strings.TrimSpace(input) != "" && (evaluated == nil || evaluated.Type() != object.ERROR_OBJ) {
+		session.inputs = append(session.inputs, input)
+	}
+}
+
+// repr returns the representation of obj, highlighted if the session uses
+// colors.
+func (session *session) repr(obj object.Object) string {
+	text := object.Repr(obj)
+	if !session.color {
+		return text
+	}
+	if obj.Type() == object.ERROR_OBJ {
+		return colorError + text + colorReset
+	}
+	return highlight(text)
+}
+
+var commands = []string{":help", ":env", ":tokens", ":ast", ":load", ":save", ":load-session", ":reset", ":quit"}
+
+const help = `:help                show this help
+:env                 list the bindings of this session
+:tokens <expr>       print the tokens of an expression
+:ast <expr>          print the syntax tree of an expression
+:load <file>         evaluate a file in this session
+:save <file>         save the inputs of this session as a script
+:load-session <file> reset the session and replay a saved script
+:reset               remove all bindings
+:quit                leave the REPL
+`
+
+// command runs a meta-command and reports whether the REPL should quit.
+func (session *session) command(line string) bool {
+	name, argument, _ := strings.Cut(line, " ")
+	argument = strings.TrimSpace(argument)
+
+	switch name {
+	case ":help":
+		io.WriteString(session.out, help)
+	case ":env":
+		session.printEnv()
+	case ":tokens":
+		session.printTokens(argument)
+	case ":ast":
+		session.printAst(argument)
+	case ":load":
+		session.load(argument)
+	case ":save":
+		session.save(argument)
+	case ":load-session":
+		if argument != "" {
+			session.reset()
+		}
+		session.load(argument)
+	case ":reset":
+		session.reset()
+	case ":quit":
+		return true
+	default:
+		fmt.Fprintf(session.out, "unknown command %s, type :help for a list of commands\n", name)
+	}
+
+	return false
+}
+
+// complete returns the meta-commands, keywords, builtins and bound names
+// starting with word.
+func (session *session) complete(line, word string) []string {
+	var names []string
+	if strings.HasPrefix(word, ":") {
+		names = commands
+	} else {
+		names = append(names, token.Keywords()...)
+		names = append(names, evaluator.BuiltinNames()...)
+		names = append(names, session.macroEnv.Names()...)
+		names = append(names, session.env.Names()...)
+	}
+
+	seen := make(map[string]bool)
+	candidates := []string{}
+	for _, name := range names {
+		if strings.HasPrefix(name, word) && !seen[name] {
+			seen[name] = true
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates
+}
+
+func (session *session) printEnv() {
+	for _, name := range session.macroEnv.Names() {
+		obj, _ := session.macroEnv.Get(name)
+		fmt.Fprintf(session.out, "%s = %s\n", name, session.repr(obj))
+	}
+	for _, name := range session.env.Names() {
+		obj, _ := session.env.Get(name)
+		fmt.Fprintf(session.out, "%s = %s\n", name, session.repr(obj))
+	}
+}
+
+func (session *session) printTokens(input string) {
+	l := lexer.New(input)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		fmt.Fprintf(session.out, "%d:%d\t%s\t%q\n", tok.Pos.Line, tok.Pos.Column, tok.Type, tok.Literal)
+	}
+}
+
+func (session *session) printAst(input string) {
+	p := parser.New(lexer.New(input))
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(session.out, p.Errors())
+		return
+	}
+
+	depth := 0
+	ast.Inspect(program, func(node ast.Node) bool {
+		if node == nil {
+			depth -= 1
+			return false
+		}
+
+		kind := strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast.")
+		fmt.Fprintf(session.out, "%s%s %s\n", strings.Repeat("  ", depth), kind, node.String())
+		depth += 1
+		return true
+	})
+}
+
+func (session *session) load(filename string) {
+	if filename == "" {
+		io.WriteString(session.out, "usage: :load <file>\n")
+		return
+	}
+
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(session.out, "%s\n", err)
+		return
+	}
 
-		evaluated := evaluator.Eval(expanded, env)
-		if evaluated != nil {
-			io.WriteString(out, evaluated.Inspect())
-			io.WriteString(out, "\n")
+	session.eval(string(source))
+}
+
+func (session *session) reset() {
+	session.env = object.NewEnvironment()
+	session.macroEnv = object.NewEnvironment()
+	session.inputs = nil
+}
+
+// save writes the inputs of the session to filename as a formatted script
+// that recreates the session when loaded.
+func (session *session) save(filename string) {
+	if filename == "" {
+		io.WriteString(session.out, "usage: :save <file>\n")
+		return
+	}
+
+	var script strings.Builder
+	for _, input := range session.inputs {
+		// Keep an input starting with an operand from continuing the
+		// expression that ends the script so far.
+		if strings.ContainsAny(strings.TrimSpace(input)[:1], "([-") && isOpen(script.String()) {
+			script.WriteString(";\n")
 		}
+		script.WriteString(input + "\n")
+	}
+
+	source, err := format.Source([]byte(script.String()))
+	if err != nil {
+		fmt.Fprintf(session.out, "%s\n", err)
+		return
+	}
+
+	if err := os.WriteFile(filename, source, 0o644); err != nil {
+		fmt.Fprintf(session.out, "%s\n", err)
+	}
+}
+
+// isOpen reports whether the last statement of input is not terminated
+// by a semicolon.
+func isOpen(input string) bool {
+	l := lexer.New(input)
+
+	last := token.Token{Type: token.SEMICOLON}
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		last = tok
 	}
+	return last.Type != token.SEMICOLON
 }
 
 func printParserErrors(out io.Writer, errors []string) {
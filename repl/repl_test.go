@@ -0,0 +1,203 @@
+package repl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"let x = 5;", false},
+		{"let add = fn(a, b) {", true},
+		{"let add = fn(a, b) {\n a + b\n};", false},
+		{"add(1,", true},
+		{"[1, 2", true},
+		{`{"a": 1,`, true},
+		{"1 +", true},
+		{"let x =", true},
+		{`"unterminated`, true},
+		{`"done"`, false},
+		{"if (x) { 1 } else", true},
+		{"}", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if actual := isIncomplete(test.input); actual != test.expected {
+			t.Errorf("isIncomplete(%q) wrong. expected=%t, got=%t", test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestStart(t *testing.T) {
+	input := `let add = fn(a, b) {
+a + b
+};
+add(1,
+2)
+:env
+:reset
+add
+let x = (1 +
+
+:quit
+1
+`
+
+	expected := `>> .. .. >> .. 3
+>> add = fn(a, b) {
+    a + b
+}
+>> >> ERROR: identifier not found: add
+>> .. 	no prefix parse function for EOF found
+	expected next token to be ), got EOF instead
+>> `
+
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+
+	if out.String() != expected {
+		t.Errorf("wrong output.\nexpected=%q\ngot=     %q", expected, out.String())
+	}
+}
+
+func TestStartReadLine(t *testing.T) {
+	input := "readLine()\nhello\nlen(readAll())\nab\ncd\n"
+
+	expected := `>> "hello"
+>> 6
+>> `
+
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+
+	if out.String() != expected {
+		t.Errorf("wrong output.\nexpected=%q\ngot=     %q", expected, out.String())
+	}
+}
+
+func TestCommands(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "lib.mk")
+	if err := os.WriteFile(filename, []byte("let double = fn(x) { x * 2 };"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{":tokens let x = 1;", "1:1\tLET\t\"let\"\n1:5\tIDENT\t\"x\"\n1:7\t=\t\"=\"\n1:9\tINT\t\"1\"\n1:10\t;\t\";\"\n"},
+		{":ast -a * b", "Program ((-a) * b)\n  ExpressionStatement ((-a) * b)\n    InfixExpression ((-a) * b)\n      PrefixExpression (-a)\n        Identifier a\n      Identifier b\n"},
+		{":load " + filename + "\ndouble(21)", "42\n"},
+		{":load", "usage: :load <file>\n"},
+		{`puts("hi")`, "hi\nnull\n"},
+		{"let s = \"hi\"; let h = {\"b\": [s], \"a\": 1};\n:env", "h = {\"a\": 1, \"b\": [\"hi\"]}\ns = \"hi\"\n"},
+		{":bogus", "unknown command :bogus, type :help for a list of commands\n"},
+		{":help", help},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		Start(strings.NewReader(test.input), &out)
+
+		actual := strings.ReplaceAll(out.String(), PROMPT, "")
+		if actual != test.expected {
+			t.Errorf("wrong output for %q.\nexpected=%q\ngot=     %q", test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	session := newSession(&bytes.Buffer{})
+	session.eval("let length = 5; let lemon = 1;")
+
+	tests := []struct {
+		word     string
+		expected []string
+	}{
+		{"le", []string{"let", "len", "lemon", "length"}},
+		{"pu", []string{"push", "puts"}},
+		{"ma", []string{"macro", "map", "match", "matchAll", "math"}},
+		{":re", []string{":reset"}},
+		{"zz", []string{}},
+	}
+
+	for _, test := range tests {
+		actual := session.complete(test.word, test.word)
+		if strings.Join(actual, " ") != strings.Join(test.expected, " ") {
+			t.Errorf("complete(%q) wrong. expected=%v, got=%v", test.word, test.expected, actual)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 5;", colorKeyword + "let" + colorReset + " x = " + colorConstant + "5" + colorReset + ";"},
+		{`puts("C:\" + x)`, colorBuiltin + "puts" + colorReset + "(" + colorString + `"C:\"` + colorReset + " + x)"},
+		{`"x" + y // note`, colorString + `"x"` + colorReset + " + y " + colorComment + "// note" + colorReset},
+		{"[true, null]", "[" + colorConstant + "true" + colorReset + ", " + colorConstant + "null" + colorReset + "]"},
+		{`"open`, colorString + `"open` + colorReset},
+	}
+
+	for _, test := range tests {
+		if actual := highlight(test.input); actual != test.expected {
+			t.Errorf("highlight(%q) wrong.\nexpected=%q\ngot=     %q", test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestSaveSession(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "session.mk")
+
+	input := `let unless = macro(cond, then) { quote(if (!(unquote(cond))) { unquote(then) }) };
+let counter = fn(start) { fn() { start + 1 } }
+missing
+let next = counter(41)
+next()
+(1 + 2) * 3
+:save ` + filename + `
+`
+
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+
+	script, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("session not saved: %s", err)
+	}
+
+	expected := `let unless = macro(cond, then) {
+    quote(if (!unquote(cond)) {
+        unquote(then)
+    })
+};
+let counter = fn(start) {
+    fn() {
+        start + 1
+    }
+};
+let next = counter(41);
+next();
+(1 + 2) * 3
+`
+	if string(script) != expected {
+		t.Errorf("wrong session script.\nexpected=%q\ngot=     %q", expected, string(script))
+	}
+
+	out.Reset()
+	Start(strings.NewReader("let next = 0\n:load-session "+filename+"\nunless(false, next())\n"), &out)
+
+	actual := strings.ReplaceAll(out.String(), PROMPT, "")
+	if actual != "9\n42\n" {
+		t.Errorf("wrong output after loading session. got=%q", actual)
+	}
+}
@@ -1,14 +1,18 @@
 package token
 
+import "sort"
+
 type Type string
 
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
+	COMMENT = "COMMENT"
 
 	// Identifiers and literals
 	IDENT  = "IDENT"
 	INT    = "INT"
+	FLOAT  = "FLOAT"
 	STRING = "STRING"
 
 	// Operators
@@ -27,6 +31,7 @@ const (
 
 	// Delimiters
 	COMMA     = ","
+	DOT       = "."
 	COLON     = ":"
 	SEMICOLON = ";"
 
@@ -46,11 +51,20 @@ const (
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	IMPORT   = "IMPORT"
 )
 
+// Position describes where a token starts in the source text.
+type Position struct {
+	Offset int `json:"offset"` // Byte offset, starting at 0
+	Line   int `json:"line"`   // Line number, starting at 1
+	Column int `json:"column"` // Column number in bytes, starting at 1
+}
+
 type Token struct {
-	Type    Type
-	Literal string
+	Type    Type     `json:"type"`
+	Literal string   `json:"literal"`
+	Pos     Position `json:"pos"`
 }
 
 var keywords = map[string]Type{
@@ -62,6 +76,7 @@ var keywords = map[string]Type{
 	"if":     IF,
 	"else":   ELSE,
 	"return": RETURN,
+	"import": IMPORT,
 }
 
 func LookupIdent(ident string) Type {
@@ -70,3 +85,13 @@ func LookupIdent(ident string) Type {
 	}
 	return IDENT
 }
+
+// Keywords returns the reserved words of the language in sorted order.
+func Keywords() []string {
+	words := make([]string, 0, len(keywords))
+	for word := range keywords {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
+}